@@ -0,0 +1,10 @@
+data "k3d_cluster" "example" {
+  name = "example-cluster"
+}
+
+provider "kubernetes" {
+  host                   = data.k3d_cluster.example.host
+  client_certificate     = base64decode(data.k3d_cluster.example.client_certificate)
+  client_key             = base64decode(data.k3d_cluster.example.client_key)
+  cluster_ca_certificate = base64decode(data.k3d_cluster.example.cluster_ca_certificate)
+}
@@ -0,0 +1,5 @@
+data "k3d_clusters" "all" {}
+
+output "stopped_clusters" {
+  value = [for cluster in data.k3d_clusters.all.clusters : cluster.name if !cluster.running]
+}
@@ -0,0 +1,19 @@
+resource "k3d_cluster" "example" {
+  name              = "example-cluster"
+  store_credentials = false
+  k3d_config        = <<EOF
+apiVersion: k3d.io/v1alpha4
+kind: Simple
+EOF
+}
+
+data "k3d_kubeconfig" "example" {
+  name = k3d_cluster.example.name
+}
+
+provider "kubernetes" {
+  host                   = data.k3d_kubeconfig.example.host
+  client_certificate     = base64decode(data.k3d_kubeconfig.example.client_certificate)
+  client_key             = base64decode(data.k3d_kubeconfig.example.client_key)
+  cluster_ca_certificate = base64decode(data.k3d_kubeconfig.example.cluster_ca_certificate)
+}
@@ -0,0 +1,2 @@
+# Import an existing k3d cluster by its name.
+terraform import k3d_cluster.example example-cluster
@@ -1,6 +1,7 @@
 resource "k3d_cluster" "example" {
-  name       = "example-cluster"
-  k3d_config = <<EOF
+  name            = "example-cluster"
+  kubeconfig_path = pathexpand("~/.kube/example-cluster.yaml")
+  k3d_config      = <<EOF
 apiVersion: k3d.io/v1alpha4
 kind: Simple
 
@@ -0,0 +1,14 @@
+resource "k3d_cluster" "example" {
+  name = "example-cluster"
+  config = {
+    agents = 1
+  }
+}
+
+resource "k3d_image_import" "example" {
+  cluster = k3d_cluster.example.name
+  images = [
+    "example/app:dev",
+    "${path.module}/worker.tar",
+  ]
+}
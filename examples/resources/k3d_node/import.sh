@@ -0,0 +1,2 @@
+# Import a k3d node created by a k3d_node resource by its container name.
+terraform import k3d_node.example k3d-example-gpu-0
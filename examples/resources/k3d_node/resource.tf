@@ -0,0 +1,15 @@
+resource "k3d_cluster" "example" {
+  name = "example-cluster"
+  config = {
+    agents = 1
+  }
+}
+
+resource "k3d_node" "gpu" {
+  name    = "example-gpu"
+  cluster = k3d_cluster.example.name
+  memory  = "2g"
+  k3s_node_labels = {
+    "example.com/gpu" = "true"
+  }
+}
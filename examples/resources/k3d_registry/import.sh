@@ -0,0 +1,2 @@
+# Import an existing k3d registry by its name.
+terraform import k3d_registry.example example-registry
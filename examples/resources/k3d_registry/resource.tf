@@ -0,0 +1,13 @@
+resource "k3d_registry" "example" {
+  name      = "example-registry"
+  host_port = 5000
+}
+
+resource "k3d_cluster" "example" {
+  name = "example-cluster"
+  config = {
+    registries = {
+      use = ["${k3d_registry.example.host}:${k3d_registry.example.host_port}"]
+    }
+  }
+}
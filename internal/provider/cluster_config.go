@@ -0,0 +1,356 @@
+package provider
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"gopkg.in/yaml.v3"
+)
+
+// ClusterConfigModel describes the structured cluster config data model,
+// which is rendered into a k3d.io/v1alpha4 Simple config.
+type ClusterConfigModel struct {
+	Servers       types.Int64                   `tfsdk:"servers"`
+	Agents        types.Int64                   `tfsdk:"agents"`
+	Image         types.String                  `tfsdk:"image"`
+	KubeAPI       *ClusterConfigKubeAPIModel    `tfsdk:"kube_api"`
+	Ports         []ClusterConfigPortModel      `tfsdk:"ports"`
+	Volumes       []ClusterConfigVolumeModel    `tfsdk:"volumes"`
+	Env           []ClusterConfigEnvModel       `tfsdk:"env"`
+	Registries    *ClusterConfigRegistriesModel `tfsdk:"registries"`
+	K3sArgs       []ClusterConfigK3sArgModel    `tfsdk:"k3s_args"`
+	K3sNodeLabels []ClusterConfigK3sLabelModel  `tfsdk:"k3s_node_labels"`
+	Options       *ClusterConfigK3dOptionsModel `tfsdk:"options"`
+}
+
+type ClusterConfigKubeAPIModel struct {
+	Host     types.String `tfsdk:"host"`
+	HostIP   types.String `tfsdk:"host_ip"`
+	HostPort types.String `tfsdk:"host_port"`
+}
+
+type ClusterConfigPortModel struct {
+	Port        types.String `tfsdk:"port"`
+	NodeFilters []string     `tfsdk:"node_filters"`
+}
+
+type ClusterConfigVolumeModel struct {
+	Volume      types.String `tfsdk:"volume"`
+	NodeFilters []string     `tfsdk:"node_filters"`
+}
+
+type ClusterConfigEnvModel struct {
+	EnvVar      types.String `tfsdk:"env_var"`
+	NodeFilters []string     `tfsdk:"node_filters"`
+}
+
+type ClusterConfigRegistriesModel struct {
+	Create *ClusterConfigRegistryCreateModel `tfsdk:"create"`
+	Use    []string                          `tfsdk:"use"`
+	Config types.String                      `tfsdk:"config"`
+}
+
+type ClusterConfigRegistryCreateModel struct {
+	Name           types.String `tfsdk:"name"`
+	Host           types.String `tfsdk:"host"`
+	HostPort       types.String `tfsdk:"host_port"`
+	ProxyRemoteURL types.String `tfsdk:"proxy_remote_url"`
+}
+
+type ClusterConfigK3sArgModel struct {
+	Arg         types.String `tfsdk:"arg"`
+	NodeFilters []string     `tfsdk:"node_filters"`
+}
+
+type ClusterConfigK3sLabelModel struct {
+	Label       types.String `tfsdk:"label"`
+	NodeFilters []string     `tfsdk:"node_filters"`
+}
+
+type ClusterConfigK3dOptionsModel struct {
+	Wait                types.Bool   `tfsdk:"wait"`
+	Timeout             types.String `tfsdk:"timeout"`
+	DisableLoadbalancer types.Bool   `tfsdk:"disable_loadbalancer"`
+	DisableImageVolume  types.Bool   `tfsdk:"disable_image_volume"`
+	DisableRollback     types.Bool   `tfsdk:"disable_rollback"`
+}
+
+// clusterConfigAttribute returns the schema of the structured cluster config.
+func clusterConfigAttribute() tfsdk.Attribute {
+	nodeFilters := tfsdk.Attribute{
+		MarkdownDescription: "Nodes to apply to, such as `server:0`, `agent:*` or `loadbalancer`. " +
+			"[See node filters in k3d documentation](https://k3d.io/v5.4.6/usage/configfile/#config-options).",
+		Optional: true,
+		Type:     types.ListType{ElemType: types.StringType},
+	}
+
+	return tfsdk.Attribute{
+		MarkdownDescription: "Structured cluster config, rendered into a `k3d.io/v1alpha4` config. " +
+			"Use instead of `k3d_config` to compose clusters with Terraform expressions " +
+			"and to validate options before creating the cluster. Conflicts with `k3d_config`.",
+		Optional: true,
+		Attributes: tfsdk.SingleNestedAttributes(map[string]tfsdk.Attribute{
+			"servers": {
+				MarkdownDescription: "Amount of server nodes. Defaults to `1`.",
+				Optional:            true,
+				Type:                types.Int64Type,
+			},
+			"agents": {
+				MarkdownDescription: "Amount of agent nodes. Defaults to `0`.",
+				Optional:            true,
+				Type:                types.Int64Type,
+			},
+			"image": {
+				MarkdownDescription: "K3s image of the nodes, such as `rancher/k3s:v1.24.4-k3s1`. " +
+					"Defaults to the image of the installed k3d version.",
+				Optional: true,
+				Type:     types.StringType,
+			},
+			"kube_api": {
+				MarkdownDescription: "Exposure of the Kubernetes API.",
+				Optional:            true,
+				Attributes: tfsdk.SingleNestedAttributes(map[string]tfsdk.Attribute{
+					"host": {
+						MarkdownDescription: "Host name added to the API server certificate.",
+						Optional:            true,
+						Type:                types.StringType,
+					},
+					"host_ip": {
+						MarkdownDescription: "Host IP the API is bound to, such as `127.0.0.1`.",
+						Optional:            true,
+						Type:                types.StringType,
+					},
+					"host_port": {
+						MarkdownDescription: "Host port the API is bound to. Defaults to a random port.",
+						Optional:            true,
+						Type:                types.StringType,
+					},
+				}),
+			},
+			"ports": {
+				MarkdownDescription: "Ports exposed on the host.",
+				Optional:            true,
+				Attributes: tfsdk.ListNestedAttributes(map[string]tfsdk.Attribute{
+					"port": {
+						MarkdownDescription: "Port mapping such as `8080:80`.",
+						Required:            true,
+						Type:                types.StringType,
+					},
+					"node_filters": nodeFilters,
+				}),
+			},
+			"volumes": {
+				MarkdownDescription: "Volumes mounted into nodes.",
+				Optional:            true,
+				Attributes: tfsdk.ListNestedAttributes(map[string]tfsdk.Attribute{
+					"volume": {
+						MarkdownDescription: "Volume mapping such as `/my/host/path:/path/in/node`.",
+						Required:            true,
+						Type:                types.StringType,
+					},
+					"node_filters": nodeFilters,
+				}),
+			},
+			"env": {
+				MarkdownDescription: "Environment variables of nodes.",
+				Optional:            true,
+				Attributes: tfsdk.ListNestedAttributes(map[string]tfsdk.Attribute{
+					"env_var": {
+						MarkdownDescription: "Environment variable such as `KEY=value`.",
+						Required:            true,
+						Type:                types.StringType,
+					},
+					"node_filters": nodeFilters,
+				}),
+			},
+			"registries": {
+				MarkdownDescription: "Container registries of the cluster.",
+				Optional:            true,
+				Attributes: tfsdk.SingleNestedAttributes(map[string]tfsdk.Attribute{
+					"create": {
+						MarkdownDescription: "Registry created with the cluster and deleted with it.",
+						Optional:            true,
+						Attributes: tfsdk.SingleNestedAttributes(map[string]tfsdk.Attribute{
+							"name": {
+								MarkdownDescription: "Registry name.",
+								Optional:            true,
+								Type:                types.StringType,
+							},
+							"host": {
+								MarkdownDescription: "Host IP the registry is bound to.",
+								Optional:            true,
+								Type:                types.StringType,
+							},
+							"host_port": {
+								MarkdownDescription: "Host port the registry is bound to.",
+								Optional:            true,
+								Type:                types.StringType,
+							},
+							"proxy_remote_url": {
+								MarkdownDescription: "Remote registry URL to use the registry as a pull through cache for, " +
+									"such as `https://registry-1.docker.io`.",
+								Optional: true,
+								Type:     types.StringType,
+							},
+						}),
+					},
+					"use": {
+						MarkdownDescription: "Existing registries to connect, such as `k3d-registry:5000`.",
+						Optional:            true,
+						Type:                types.ListType{ElemType: types.StringType},
+					},
+					"config": {
+						MarkdownDescription: "K3s registries config content. " +
+							"[See private registry configuration in k3s documentation](https://docs.k3s.io/installation/private-registry).",
+						Optional: true,
+						Type:     types.StringType,
+					},
+				}),
+			},
+			"k3s_args": {
+				MarkdownDescription: "Additional arguments for the k3s server and agent processes.",
+				Optional:            true,
+				Attributes: tfsdk.ListNestedAttributes(map[string]tfsdk.Attribute{
+					"arg": {
+						MarkdownDescription: "Argument such as `--disable=traefik`.",
+						Required:            true,
+						Type:                types.StringType,
+					},
+					"node_filters": nodeFilters,
+				}),
+			},
+			"k3s_node_labels": {
+				MarkdownDescription: "Kubernetes labels of nodes.",
+				Optional:            true,
+				Attributes: tfsdk.ListNestedAttributes(map[string]tfsdk.Attribute{
+					"label": {
+						MarkdownDescription: "Label such as `foo=bar`.",
+						Required:            true,
+						Type:                types.StringType,
+					},
+					"node_filters": nodeFilters,
+				}),
+			},
+			"options": {
+				MarkdownDescription: "K3d options.",
+				Optional:            true,
+				Attributes: tfsdk.SingleNestedAttributes(map[string]tfsdk.Attribute{
+					"wait": {
+						MarkdownDescription: "Wait for the nodes to be ready. Defaults to `true`.",
+						Optional:            true,
+						Type:                types.BoolType,
+					},
+					"timeout": {
+						MarkdownDescription: "Timeout for creating the cluster, such as `60s`.",
+						Optional:            true,
+						Type:                types.StringType,
+					},
+					"disable_loadbalancer": {
+						MarkdownDescription: "Create the cluster without a load balancer node.",
+						Optional:            true,
+						Type:                types.BoolType,
+					},
+					"disable_image_volume": {
+						MarkdownDescription: "Create the cluster without a volume for importing images.",
+						Optional:            true,
+						Type:                types.BoolType,
+					},
+					"disable_rollback": {
+						MarkdownDescription: "Keep the nodes when creating the cluster fails.",
+						Optional:            true,
+						Type:                types.BoolType,
+					},
+				}),
+			},
+		}),
+	}
+}
+
+// renderK3dConfig renders a structured cluster config into k3d config content.
+func renderK3dConfig(model ClusterConfigModel) (string, error) {
+	config := K3dSimpleConfig{
+		APIVersion: "k3d.io/v1alpha4",
+		Kind:       "Simple",
+		Servers:    1,
+		Agents:     0,
+		Image:      model.Image.ValueString(),
+	}
+	if !model.Servers.IsNull() {
+		config.Servers = int(model.Servers.ValueInt64())
+	}
+	if !model.Agents.IsNull() {
+		config.Agents = int(model.Agents.ValueInt64())
+	}
+
+	if model.KubeAPI != nil {
+		config.KubeAPI = &K3dSimpleConfigKubeAPI{
+			Host:     model.KubeAPI.Host.ValueString(),
+			HostIP:   model.KubeAPI.HostIP.ValueString(),
+			HostPort: model.KubeAPI.HostPort.ValueString(),
+		}
+	}
+	for _, port := range model.Ports {
+		config.Ports = append(config.Ports, K3dSimpleConfigPort{Port: port.Port.ValueString(), NodeFilters: port.NodeFilters})
+	}
+	for _, volume := range model.Volumes {
+		config.Volumes = append(config.Volumes, K3dSimpleConfigVolume{Volume: volume.Volume.ValueString(), NodeFilters: volume.NodeFilters})
+	}
+	for _, env := range model.Env {
+		config.Env = append(config.Env, K3dSimpleConfigEnv{EnvVar: env.EnvVar.ValueString(), NodeFilters: env.NodeFilters})
+	}
+
+	if model.Registries != nil {
+		config.Registries = &K3dSimpleConfigRegistries{
+			Use:    model.Registries.Use,
+			Config: model.Registries.Config.ValueString(),
+		}
+		if create := model.Registries.Create; create != nil {
+			config.Registries.Create = &K3dSimpleConfigRegistryCreate{
+				Name:     create.Name.ValueString(),
+				Host:     create.Host.ValueString(),
+				HostPort: create.HostPort.ValueString(),
+			}
+			if !create.ProxyRemoteURL.IsNull() {
+				config.Registries.Create.Proxy = &K3dSimpleConfigRegistryProxy{RemoteURL: create.ProxyRemoteURL.ValueString()}
+			}
+		}
+	}
+
+	if model.Options != nil || len(model.K3sArgs) > 0 || len(model.K3sNodeLabels) > 0 {
+		config.Options = &K3dSimpleConfigOptions{}
+	}
+	if options := model.Options; options != nil {
+		config.Options.K3d = &K3dSimpleConfigK3dOptions{
+			Wait:                boolPointer(options.Wait),
+			Timeout:             options.Timeout.ValueString(),
+			DisableLoadbalancer: boolPointer(options.DisableLoadbalancer),
+			DisableImageVolume:  boolPointer(options.DisableImageVolume),
+			DisableRollback:     boolPointer(options.DisableRollback),
+		}
+	}
+	if len(model.K3sArgs) > 0 || len(model.K3sNodeLabels) > 0 {
+		config.Options.K3s = &K3dSimpleConfigK3sOptions{}
+		for _, arg := range model.K3sArgs {
+			config.Options.K3s.ExtraArgs = append(config.Options.K3s.ExtraArgs, K3dSimpleConfigArg{Arg: arg.Arg.ValueString(), NodeFilters: arg.NodeFilters})
+		}
+		for _, label := range model.K3sNodeLabels {
+			config.Options.K3s.NodeLabels = append(config.Options.K3s.NodeLabels, K3dSimpleConfigLabel{Label: label.Label.ValueString(), NodeFilters: label.NodeFilters})
+		}
+	}
+
+	content, err := yaml.Marshal(config)
+	if err != nil {
+		return "", fmt.Errorf("failed rendering k3d config: %w", err)
+	}
+	return string(content), nil
+}
+
+// boolPointer returns a pointer to the value of b, or nil when b is null.
+func boolPointer(b types.Bool) *bool {
+	if b.IsNull() {
+		return nil
+	}
+	value := b.ValueBool()
+	return &value
+}
@@ -0,0 +1,80 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestRenderK3dConfig(t *testing.T) {
+	content, err := renderK3dConfig(ClusterConfigModel{
+		Agents: types.Int64Value(2),
+		Image:  types.StringValue("rancher/k3s:v1.24.4-k3s1"),
+		Ports: []ClusterConfigPortModel{
+			{Port: types.StringValue("3080:80"), NodeFilters: []string{"loadbalancer"}},
+		},
+		Env: []ClusterConfigEnvModel{
+			{EnvVar: types.StringValue("FOO=bar"), NodeFilters: []string{"server:*"}},
+		},
+		Registries: &ClusterConfigRegistriesModel{
+			Create: &ClusterConfigRegistryCreateModel{
+				Name:           types.StringValue("dev"),
+				HostPort:       types.StringValue("5000"),
+				ProxyRemoteURL: types.StringValue("https://registry-1.docker.io"),
+			},
+		},
+		K3sArgs: []ClusterConfigK3sArgModel{
+			{Arg: types.StringValue("--disable=traefik"), NodeFilters: []string{"server:*"}},
+		},
+		Options: &ClusterConfigK3dOptionsModel{
+			Wait: types.BoolValue(false),
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `apiVersion: k3d.io/v1alpha4
+kind: Simple
+servers: 1
+agents: 2
+image: rancher/k3s:v1.24.4-k3s1
+ports:
+    - port: 3080:80
+      nodeFilters:
+        - loadbalancer
+env:
+    - envVar: FOO=bar
+      nodeFilters:
+        - server:*
+registries:
+    create:
+        name: dev
+        hostPort: "5000"
+        proxy:
+            remoteURL: https://registry-1.docker.io
+options:
+    k3d:
+        wait: false
+    k3s:
+        extraArgs:
+            - arg: --disable=traefik
+              nodeFilters:
+                - server:*
+`
+	if content != want {
+		t.Errorf("expected config:\n%s\ngot:\n%s", want, content)
+	}
+}
+
+func TestRenderK3dConfigDefaults(t *testing.T) {
+	content, err := renderK3dConfig(ClusterConfigModel{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "apiVersion: k3d.io/v1alpha4\nkind: Simple\nservers: 1\nagents: 0\n"
+	if content != want {
+		t.Errorf("expected config %q, got %q", want, content)
+	}
+}
@@ -0,0 +1,203 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces
+var _ datasource.DataSource = &ClusterDataSource{}
+var _ datasource.DataSourceWithConfigure = &ClusterDataSource{}
+
+func NewClusterDataSource() datasource.DataSource {
+	return &ClusterDataSource{}
+}
+
+// ClusterDataSource defines the data source implementation.
+type ClusterDataSource struct {
+	runner K3dRunner
+}
+
+// ClusterDataSourceModel describes the data source data model.
+type ClusterDataSourceModel struct {
+	ID                   types.String `tfsdk:"id"`
+	Name                 types.String `tfsdk:"name"`
+	Running              types.Bool   `tfsdk:"running"`
+	Kubeconfig           types.String `tfsdk:"kubeconfig"`
+	ContextName          types.String `tfsdk:"context_name"`
+	ClusterName          types.String `tfsdk:"cluster_name"`
+	Host                 types.String `tfsdk:"host"`
+	ClientCertificate    types.String `tfsdk:"client_certificate"`
+	ClientKey            types.String `tfsdk:"client_key"`
+	ClusterCACertificate types.String `tfsdk:"cluster_ca_certificate"`
+	Token                types.String `tfsdk:"token"`
+}
+
+func (d *ClusterDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_cluster"
+}
+
+func (d *ClusterDataSource) GetSchema(ctx context.Context) (tfsdk.Schema, diag.Diagnostics) {
+	return tfsdk.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "The data source `k3d_cluster` reads an existing k3d cluster by name.\n" +
+			"\n" +
+			"Use it to connect the Kubernetes and Helm providers to a cluster " +
+			"created outside of the current Terraform configuration.",
+
+		Attributes: map[string]tfsdk.Attribute{
+			"id": {
+				MarkdownDescription: "Used internally by the provider.",
+				Type:                types.StringType,
+				Computed:            true,
+			},
+			"name": {
+				MarkdownDescription: "Cluster name.",
+				Required:            true,
+				Type:                types.StringType,
+			},
+			"running": {
+				MarkdownDescription: "Whether all server nodes of the cluster are running.",
+				Type:                types.BoolType,
+				Computed:            true,
+			},
+			"kubeconfig": {
+				MarkdownDescription: "Kubeconfig content. " +
+					"Dump in a file and point the `KUBECONFIG` environment variable or `--kubeconfig` " +
+					"flag at it to use kubectl or Helm with the cluster.",
+				Type:      types.StringType,
+				Computed:  true,
+				Sensitive: true,
+			},
+			"context_name": {
+				MarkdownDescription: "Name of the current context of the kubeconfig, such as `k3d-example`.",
+				Type:                types.StringType,
+				Computed:            true,
+			},
+			"cluster_name": {
+				MarkdownDescription: "Name of the cluster of the current context in the kubeconfig, such as `k3d-example`.",
+				Type:                types.StringType,
+				Computed:            true,
+			},
+			"host": {
+				MarkdownDescription: "Cluster host. " +
+					"Use to authenticate other providers with the cluster. " +
+					"Pass to `host` attribute when " +
+					"[configuring Kubernetes](https://registry.terraform.io/providers/hashicorp/kubernetes/latest/docs/guides/getting-started#provider-setup) " +
+					"or [Helm providers](https://registry.terraform.io/providers/hashicorp/helm/latest/docs#credentials-config).",
+				Type:     types.StringType,
+				Computed: true,
+			},
+			"client_certificate": {
+				MarkdownDescription: "Client certificate encoded in base 64. " +
+					"Use to authenticate other providers with the cluster. " +
+					"Use `base64decode` and pass to `client_certificate` attribute when " +
+					"[configuring Kubernetes](https://registry.terraform.io/providers/hashicorp/kubernetes/latest/docs/guides/getting-started#provider-setup) " +
+					"or [Helm providers](https://registry.terraform.io/providers/hashicorp/helm/latest/docs#credentials-config).",
+				Type:      types.StringType,
+				Computed:  true,
+				Sensitive: true,
+			},
+			"client_key": {
+				MarkdownDescription: "Client key encoded in base 64. " +
+					"Use to authenticate other providers with the cluster. " +
+					"Use `base64decode` and pass to `client_key` attribute when " +
+					"[configuring Kubernetes](https://registry.terraform.io/providers/hashicorp/kubernetes/latest/docs/guides/getting-started#provider-setup) " +
+					"or [Helm providers](https://registry.terraform.io/providers/hashicorp/helm/latest/docs#credentials-config).",
+				Type:      types.StringType,
+				Computed:  true,
+				Sensitive: true,
+			},
+			"cluster_ca_certificate": {
+				MarkdownDescription: "Cluster CA certificate encoded in base 64. " +
+					"Use to authenticate other providers with the cluster. " +
+					"Use `base64decode` and pass to `cluster_ca_certificate` attribute when " +
+					"[configuring Kubernetes](https://registry.terraform.io/providers/hashicorp/kubernetes/latest/docs/guides/getting-started#provider-setup) " +
+					"or [Helm providers](https://registry.terraform.io/providers/hashicorp/helm/latest/docs#credentials-config).",
+				Type:      types.StringType,
+				Computed:  true,
+				Sensitive: true,
+			},
+			"token": {
+				MarkdownDescription: "Bearer token of the kubeconfig user. " +
+					"Empty when the user authenticates with a client certificate. " +
+					"Users authenticating with an exec plugin have neither a token nor a client certificate, " +
+					"use `kubeconfig` for them instead.",
+				Type:      types.StringType,
+				Computed:  true,
+				Sensitive: true,
+			},
+		},
+	}, nil
+}
+
+func (d *ClusterDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	runner, ok := req.ProviderData.(K3dRunner)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected K3dRunner, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.runner = runner
+}
+
+func (d *ClusterDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data ClusterDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	clusters, diags := listClusters(ctx, d.runner)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	cluster, err := findCluster(clusters, data.Name.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("name"),
+			"k3d cluster not found",
+			fmt.Sprintf("A k3d cluster named %q does not exist. List existing clusters with `k3d cluster list`.", data.Name.ValueString()))
+		return
+	}
+	data.ID = types.StringValue(cluster.Name)
+	data.Running = types.BoolValue(cluster.Running())
+
+	credentials, content, diags := getKubeconfig(ctx, d.runner, cluster.Name)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	data.ContextName = types.StringValue(credentials.ContextName)
+	data.ClusterName = types.StringValue(credentials.ClusterName)
+	data.Host = types.StringValue(credentials.Cluster.Server)
+	data.ClusterCACertificate = types.StringValue(credentials.Cluster.CertificateAuthorityData)
+	data.ClientCertificate = types.StringValue(credentials.User.ClientCertificateData)
+	data.ClientKey = types.StringValue(credentials.User.ClientKeyData)
+	data.Token = types.StringValue(credentials.User.Token)
+	data.Kubeconfig = types.StringValue(content)
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
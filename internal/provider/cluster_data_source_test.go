@@ -0,0 +1,112 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccClusterDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Read testing
+			{
+				Config: testAccClusterDataSourceConfig(),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.k3d_cluster.test", "name", "k3d-provider-test"),
+					resource.TestCheckResourceAttr("data.k3d_cluster.test", "running", "true"),
+					resource.TestCheckResourceAttrPair("data.k3d_cluster.test", "host", "k3d_cluster.test", "host"),
+					resource.TestCheckResourceAttrSet("data.k3d_cluster.test", "client_certificate"),
+					resource.TestCheckResourceAttrSet("data.k3d_cluster.test", "client_key"),
+					resource.TestCheckResourceAttrSet("data.k3d_cluster.test", "cluster_ca_certificate"),
+				),
+			},
+		},
+	})
+}
+
+func testAccClusterDataSourceConfig() string {
+	return `
+resource "k3d_cluster" "test" {
+	name = "k3d-provider-test"
+  k3d_config = <<EOF
+apiVersion: k3d.io/v1alpha4
+kind: Simple
+EOF
+}
+
+data "k3d_cluster" "test" {
+	name = resource.k3d_cluster.test.name
+}
+`
+}
+
+func TestClusterDataSourceRead(t *testing.T) {
+	runner := (&fakeK3dRunner{}).
+		On(testClusterList, nil, "cluster", "list").
+		On(testKubeconfig, nil, "kubeconfig", "get", "test")
+	d := &ClusterDataSource{runner: runner}
+
+	config, state := newTestClusterDataSourceConfig(t, "test")
+	resp := &datasource.ReadResponse{State: state}
+	d.Read(context.Background(), datasource.ReadRequest{Config: config}, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+	var data ClusterDataSourceModel
+	if diags := resp.State.Get(context.Background(), &data); diags.HasError() {
+		t.Fatalf("unexpected state error: %v", diags)
+	}
+	if got := data.Host.ValueString(); got != "https://0.0.0.0:40123" {
+		t.Errorf("expected host https://0.0.0.0:40123, got %s", got)
+	}
+	if got := data.ClientKey.ValueString(); got != "a2V5LWRhdGE=" {
+		t.Errorf("expected client_key a2V5LWRhdGE=, got %s", got)
+	}
+	if !data.Running.ValueBool() {
+		t.Error("expected running to be true")
+	}
+}
+
+func TestClusterDataSourceReadMissing(t *testing.T) {
+	runner := (&fakeK3dRunner{}).
+		On("[]", nil, "cluster", "list")
+	d := &ClusterDataSource{runner: runner}
+
+	config, state := newTestClusterDataSourceConfig(t, "test")
+	resp := &datasource.ReadResponse{State: state}
+	d.Read(context.Background(), datasource.ReadRequest{Config: config}, resp)
+
+	if !resp.Diagnostics.HasError() {
+		t.Fatal("expected error")
+	}
+	if got := resp.Diagnostics[0].Summary(); got != "k3d cluster not found" {
+		t.Errorf("expected not found error, got %q", got)
+	}
+}
+
+// newTestClusterDataSourceConfig returns a config reading the cluster called
+// name and an empty state.
+func newTestClusterDataSourceConfig(t *testing.T, name string) (tfsdk.Config, tfsdk.State) {
+	schema, diags := (&ClusterDataSource{}).GetSchema(context.Background())
+	if diags.HasError() {
+		t.Fatalf("unexpected schema error: %v", diags)
+	}
+	state := tfsdk.State{
+		Schema: schema,
+		Raw:    tftypes.NewValue(schema.Type().TerraformType(context.Background()), nil),
+	}
+	config := state
+	if diags := config.Set(context.Background(), &ClusterDataSourceModel{Name: types.StringValue(name)}); diags.HasError() {
+		t.Fatalf("unexpected config error: %v", diags)
+	}
+	return tfsdk.Config{Schema: schema, Raw: config.Raw}, state
+}
@@ -0,0 +1,370 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"gopkg.in/yaml.v3"
+)
+
+// validateClusterKubeconfig checks that switch_context is only enabled
+// together with merge_default_kubeconfig.
+func validateClusterKubeconfig(ctx context.Context, config tfsdk.Config) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	var merge, switchContext types.Bool
+	diags.Append(config.GetAttribute(ctx, path.Root("merge_default_kubeconfig"), &merge)...)
+	diags.Append(config.GetAttribute(ctx, path.Root("switch_context"), &switchContext)...)
+	if diags.HasError() || merge.IsUnknown() || switchContext.IsUnknown() {
+		return diags
+	}
+
+	if switchContext.ValueBool() && !merge.ValueBool() {
+		diags.AddAttributeError(
+			path.Root("switch_context"),
+			"Invalid kubeconfig options",
+			"The current context can only be switched in the default kubeconfig. "+
+				"Set `merge_default_kubeconfig` to `true` to use `switch_context`.")
+	}
+	return diags
+}
+
+// exportKubeconfig writes the kubeconfig of data to kubeconfig_path and merges
+// it into the default kubeconfig as configured. The file at the kubeconfig
+// path of the prior state is removed when the path changed, and the merged
+// cluster is removed from the default kubeconfig when merging was disabled.
+func (r *ClusterResource) exportKubeconfig(ctx context.Context, data *ClusterResourceModel, state *ClusterResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if state != nil && !state.KubeconfigPath.IsNull() && !state.KubeconfigPath.Equal(data.KubeconfigPath) {
+		if err := removeKubeconfigFile(state.KubeconfigPath.ValueString()); err != nil {
+			// Continue because it is not a critical problem.
+			diags.AddWarning("Failed removing previous kubeconfig file", fmt.Sprint(err))
+		}
+	}
+
+	data.KubeconfigFileChecksum = types.StringNull()
+	if !data.KubeconfigPath.IsNull() {
+		if err := writeKubeconfigFile(data.KubeconfigPath.ValueString(), data.Kubeconfig.ValueString()); err != nil {
+			diags.AddAttributeError(path.Root("kubeconfig_path"), "Failed writing kubeconfig file", fmt.Sprint(err))
+			return diags
+		}
+		data.KubeconfigFileChecksum = types.StringValue(kubeconfigChecksum(data.Kubeconfig.ValueString()))
+		tflog.Info(ctx, "wrote kubeconfig file", map[string]interface{}{"path": data.KubeconfigPath.ValueString()})
+	}
+
+	if state != nil && state.MergeDefaultKubeconfig.ValueBool() && !data.MergeDefaultKubeconfig.ValueBool() {
+		diags.Append(r.removeMergedKubeconfig(ctx, data.Name.ValueString())...)
+	}
+
+	if data.MergeDefaultKubeconfig.ValueBool() {
+		output, err := r.runner.Run(ctx, "kubeconfig", "merge", data.Name.ValueString(),
+			"--kubeconfig-merge-default",
+			"--kubeconfig-switch-context="+strconv.FormatBool(data.SwitchContext.ValueBool()))
+		if err != nil {
+			addK3dError(&diags, "Failed merging kubeconfig into the default kubeconfig", k3dObject{Kind: "cluster", Name: data.Name.ValueString()}, output, err)
+			return diags
+		}
+		tflog.Info(ctx, "merged kubeconfig into the default kubeconfig")
+	}
+	return diags
+}
+
+// writeKubeconfigFile writes the kubeconfig content to a file only readable
+// by the current user, creating its directory when needed.
+func writeKubeconfigFile(name string, content string) error {
+	if err := os.MkdirAll(filepath.Dir(name), 0700); err != nil {
+		return err
+	}
+	if err := os.WriteFile(name, []byte(content), 0600); err != nil {
+		return err
+	}
+	// WriteFile keeps the permissions of existing files.
+	return os.Chmod(name, 0600)
+}
+
+// removeKubeconfigFile removes a kubeconfig file written by
+// writeKubeconfigFile. Files which do not exist are ignored.
+func removeKubeconfigFile(name string) error {
+	if err := os.Remove(name); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// kubeconfigChecksum returns the SHA-256 checksum of kubeconfig content, in
+// the format of fileChecksum.
+func kubeconfigChecksum(content string) string {
+	return fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(content)))
+}
+
+// kubeconfigFileChanged reports whether the file at name does not hold the
+// kubeconfig with the checksum, including when it was removed.
+func kubeconfigFileChanged(name string, checksum string) bool {
+	current, err := fileChecksum(name)
+	return err != nil || current != checksum
+}
+
+// removeMergedKubeconfig removes the cluster called name from the default
+// kubeconfig k3d merged it into. Failures are warnings, because the merged
+// cluster does not affect the k3d cluster.
+func (r *ClusterResource) removeMergedKubeconfig(ctx context.Context, name string) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	kubeconfigEnv := os.Getenv("KUBECONFIG")
+	if runner, ok := r.runner.(*ExecK3dRunner); ok {
+		if value, ok := runner.Env["KUBECONFIG"]; ok {
+			kubeconfigEnv = value
+		}
+	}
+	defaultPath, err := defaultKubeconfigPath(kubeconfigEnv)
+	if err == nil {
+		err = removeKubeconfigCluster(defaultPath, name)
+	}
+	if err != nil {
+		diags.AddWarning(
+			"Failed removing cluster from the default kubeconfig",
+			fmt.Sprintf("Remove the context with `kubectl config delete-context k3d-%s`.\n\n%s", name, err))
+		return diags
+	}
+	tflog.Info(ctx, "removed cluster from the default kubeconfig", map[string]interface{}{"path": defaultPath})
+	return diags
+}
+
+// defaultKubeconfigPath returns the default kubeconfig k3d merges clusters
+// into: the first existing file of the KUBECONFIG environment variable value,
+// its first file when none exists, or ~/.kube/config when it is empty.
+func defaultKubeconfigPath(kubeconfigEnv string) (string, error) {
+	var paths []string
+	for _, name := range filepath.SplitList(kubeconfigEnv) {
+		if name != "" {
+			paths = append(paths, name)
+		}
+	}
+	for _, name := range paths {
+		if _, err := os.Stat(name); err == nil {
+			return name, nil
+		}
+	}
+	if len(paths) > 0 {
+		return paths[0], nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".kube", "config"), nil
+}
+
+// removeKubeconfigCluster removes the cluster, user and context k3d merges
+// for the cluster called name from the kubeconfig file at filename, the way
+// k3d does when deleting the cluster. Missing files are ignored.
+func removeKubeconfigCluster(filename string, name string) error {
+	content, err := os.ReadFile(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var document yaml.Node
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		return fmt.Errorf("failed parsing kubeconfig: %w", err)
+	}
+	if len(document.Content) == 0 {
+		return nil
+	}
+
+	root := document.Content[0]
+	contextName := "k3d-" + name
+	removed := removeYAMLNamedEntry(yamlMappingValue(root, "clusters"), contextName)
+	removed = removeYAMLNamedEntry(yamlMappingValue(root, "users"), "admin@"+contextName) || removed
+	removed = removeYAMLNamedEntry(yamlMappingValue(root, "contexts"), contextName) || removed
+	if current := yamlMappingValue(root, "current-context"); current != nil && current.Value == contextName {
+		current.Value = ""
+		removed = true
+	}
+	if !removed {
+		return nil
+	}
+
+	rewritten, err := yaml.Marshal(&document)
+	if err != nil {
+		return fmt.Errorf("failed rendering kubeconfig: %w", err)
+	}
+	info, err := os.Stat(filename)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, rewritten, info.Mode().Perm())
+}
+
+// removeYAMLNamedEntry removes the entries with the given name from a YAML
+// sequence of mappings, and reports whether any was removed.
+func removeYAMLNamedEntry(sequence *yaml.Node, name string) bool {
+	if sequence == nil || sequence.Kind != yaml.SequenceNode {
+		return false
+	}
+	var kept []*yaml.Node
+	for _, entry := range sequence.Content {
+		if value := yamlMappingValue(entry, "name"); value == nil || value.Value != name {
+			kept = append(kept, entry)
+		}
+	}
+	removed := len(kept) != len(sequence.Content)
+	sequence.Content = kept
+	return removed
+}
+
+// validateClusterAPIHost checks that api_host_override is a host with an
+// optional port.
+func validateClusterAPIHost(ctx context.Context, config tfsdk.Config) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	var apiHost types.String
+	diags.Append(config.GetAttribute(ctx, path.Root("api_host_override"), &apiHost)...)
+	if diags.HasError() || apiHost.IsNull() || apiHost.IsUnknown() {
+		return diags
+	}
+
+	if _, _, err := splitAPIHost(apiHost.ValueString()); err != nil {
+		diags.AddAttributeError(
+			path.Root("api_host_override"),
+			"Invalid API host",
+			fmt.Sprintf("Expected a host name or IP address with an optional port, such as \"host.docker.internal\" or \"192.168.1.10:6443\": %s.", err))
+	}
+	return diags
+}
+
+// splitAPIHost splits an API host override into the host and the port, which
+// is empty when the override has no port.
+func splitAPIHost(apiHost string) (string, string, error) {
+	if apiHost == "" || strings.ContainsAny(apiHost, "/?#@ ") {
+		return "", "", fmt.Errorf("invalid host %q", apiHost)
+	}
+	host, port, err := net.SplitHostPort(apiHost)
+	if err != nil {
+		// Hosts without port, including IPv6 addresses.
+		return strings.TrimSuffix(strings.TrimPrefix(apiHost, "["), "]"), "", nil
+	}
+	if _, err := strconv.ParseUint(port, 10, 16); err != nil || host == "" {
+		return "", "", fmt.Errorf("invalid host %q", apiHost)
+	}
+	return host, port, nil
+}
+
+// apiHostTLSSANArgs returns the k3d arguments adding the host of the API host
+// override to the certificate of the API server, so clients can verify it
+// when connecting through the override.
+func apiHostTLSSANArgs(apiHost string) []string {
+	host, _, err := splitAPIHost(apiHost)
+	if err != nil {
+		return nil
+	}
+	return []string{"--k3s-arg", fmt.Sprintf("--tls-san=%s@server:*", host)}
+}
+
+// overrideAPIServer returns the server URL with the host, and the port when
+// set, of the API host override.
+func overrideAPIServer(server string, apiHost string) (string, error) {
+	u, err := url.Parse(server)
+	if err != nil {
+		return "", fmt.Errorf("failed parsing server %q: %w", server, err)
+	}
+	host, port, err := splitAPIHost(apiHost)
+	if err != nil {
+		return "", err
+	}
+	if port == "" {
+		port = u.Port()
+	}
+	if port == "" {
+		u.Host = host
+		if strings.Contains(host, ":") {
+			u.Host = "[" + host + "]"
+		}
+	} else {
+		u.Host = net.JoinHostPort(host, port)
+	}
+	return u.String(), nil
+}
+
+// applyAPIHostOverride overrides the server of the credentials and of the
+// kubeconfig content with the API host override, returning the new content.
+func applyAPIHostOverride(credentials *KubeconfigCredentials, content string, apiHost string) (string, error) {
+	server, err := overrideAPIServer(credentials.Cluster.Server, apiHost)
+	if err != nil {
+		return "", err
+	}
+	content, err = overrideKubeconfigServer(content, credentials.ClusterName, apiHost)
+	if err != nil {
+		return "", err
+	}
+	credentials.Cluster.Server = server
+	return content, nil
+}
+
+// overrideKubeconfigServer rewrites the server of the kubeconfig cluster
+// called clusterName with the API host override.
+func overrideKubeconfigServer(content string, clusterName string, apiHost string) (string, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal([]byte(content), &document); err != nil {
+		return "", fmt.Errorf("failed parsing kubeconfig: %w", err)
+	}
+	if len(document.Content) == 0 {
+		return content, nil
+	}
+
+	clusters := yamlMappingValue(document.Content[0], "clusters")
+	if clusters == nil || clusters.Kind != yaml.SequenceNode {
+		return content, nil
+	}
+	for _, entry := range clusters.Content {
+		name := yamlMappingValue(entry, "name")
+		if name == nil || name.Value != clusterName {
+			continue
+		}
+		server := yamlMappingValue(yamlMappingValue(entry, "cluster"), "server")
+		if server == nil {
+			continue
+		}
+		overridden, err := overrideAPIServer(server.Value, apiHost)
+		if err != nil {
+			return "", err
+		}
+		server.Value = overridden
+	}
+
+	rewritten, err := yaml.Marshal(&document)
+	if err != nil {
+		return "", fmt.Errorf("failed rendering kubeconfig: %w", err)
+	}
+	return string(rewritten), nil
+}
+
+// yamlMappingValue returns the value of key in a YAML mapping, or nil when the
+// node is not a mapping or does not have the key.
+func yamlMappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,526 @@
+package provider
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	fwresource "github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"gopkg.in/yaml.v3"
+)
+
+const testKubeconfigMultiple = `apiVersion: v1
+clusters:
+- cluster:
+    server: https://0.0.0.0:40123
+  name: k3d-other
+- cluster:
+    certificate-authority-data: Y2EtZGF0YQ==
+    server: https://0.0.0.0:40124
+  name: k3d-test
+contexts:
+- context:
+    cluster: k3d-other
+    user: admin@k3d-other
+  name: k3d-other
+- context:
+    cluster: k3d-test
+    user: token@k3d-test
+  name: k3d-test
+current-context: k3d-test
+kind: Config
+users:
+- name: admin@k3d-other
+  user:
+    client-certificate-data: Y2VydC1kYXRh
+    client-key-data: a2V5LWRhdGE=
+- name: token@k3d-test
+  user:
+    token: secret
+- name: exec@k3d-test
+  user:
+    exec:
+      apiVersion: client.authentication.k8s.io/v1beta1
+      command: example-login
+`
+
+func TestKubeconfigCredentials(t *testing.T) {
+	cases := []struct {
+		name        string
+		kubeconfig  string
+		wantContext string
+		wantCluster string
+		wantServer  string
+		wantToken   string
+		wantErr     bool
+	}{
+		{"single", testKubeconfig, "k3d-test", "k3d-test", "https://0.0.0.0:40123", "", false},
+		{"current context", testKubeconfigMultiple, "k3d-test", "k3d-test", "https://0.0.0.0:40124", "secret", false},
+		{"missing current context", strings.Replace(testKubeconfigMultiple, "current-context: k3d-test", "current-context: k3d-missing", 1), "", "", "", "", true},
+		{"no current context", strings.Replace(testKubeconfigMultiple, "current-context: k3d-test", "", 1), "", "", "", "", true},
+		{"missing user", strings.Replace(testKubeconfigMultiple, "user: token@k3d-test", "user: missing@k3d-test", 1), "", "", "", "", true},
+		{"no contexts", "clusters:\n- name: k3d-test\n  cluster:\n    server: https://0.0.0.0:40123\nusers:\n- name: admin\n  user:\n    token: secret\n", "", "k3d-test", "https://0.0.0.0:40123", "secret", false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var kubeconfig Kubeconfig
+			if err := yaml.Unmarshal([]byte(c.kubeconfig), &kubeconfig); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			credentials, err := kubeconfig.Credentials()
+			if (err != nil) != c.wantErr {
+				t.Fatalf("expected error %t, got %v", c.wantErr, err)
+			}
+			if c.wantErr {
+				return
+			}
+			if credentials.ContextName != c.wantContext || credentials.ClusterName != c.wantCluster {
+				t.Errorf("expected context %q and cluster %q, got %q and %q", c.wantContext, c.wantCluster, credentials.ContextName, credentials.ClusterName)
+			}
+			if credentials.Cluster.Server != c.wantServer {
+				t.Errorf("expected server %s, got %s", c.wantServer, credentials.Cluster.Server)
+			}
+			if credentials.User.Token != c.wantToken {
+				t.Errorf("expected token %q, got %q", c.wantToken, credentials.User.Token)
+			}
+		})
+	}
+}
+
+func TestKubeconfigCredentialsExec(t *testing.T) {
+	var kubeconfig Kubeconfig
+	content := strings.Replace(testKubeconfigMultiple, "user: token@k3d-test", "user: exec@k3d-test", 1)
+	if err := yaml.Unmarshal([]byte(content), &kubeconfig); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	credentials, err := kubeconfig.Credentials()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if credentials.User.Exec == nil || credentials.User.Exec.Command != "example-login" {
+		t.Errorf("expected exec user, got %+v", credentials.User)
+	}
+}
+
+func TestClusterResourceCreateKubeconfigMultipleContexts(t *testing.T) {
+	runner := (&fakeK3dRunner{}).
+		On("", nil, "cluster", "create", "test").
+		On(testKubeconfigMultiple, nil, "kubeconfig", "get", "test")
+	r := &ClusterResource{runner: runner}
+
+	req := fwresource.CreateRequest{Plan: newTestPlan(t, testResourceSchema(t, &ClusterResource{}), ClusterResourceModel{
+		Name:      types.StringValue("test"),
+		K3dConfig: types.StringValue("apiVersion: k3d.io/v1alpha4\nkind: Simple\n"),
+	})}
+	resp := &fwresource.CreateResponse{State: newTestState(t, testResourceSchema(t, &ClusterResource{}), nil)}
+	r.Create(context.Background(), req, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+	data := getTestModel[ClusterResourceModel](t, resp.State)
+	if got := data.ContextName.ValueString(); got != "k3d-test" {
+		t.Errorf("expected context_name k3d-test, got %s", got)
+	}
+	if got := data.ClusterName.ValueString(); got != "k3d-test" {
+		t.Errorf("expected cluster_name k3d-test, got %s", got)
+	}
+	if got := data.Host.ValueString(); got != "https://0.0.0.0:40124" {
+		t.Errorf("expected host of the current context, got %s", got)
+	}
+	if got := data.Token.ValueString(); got != "secret" {
+		t.Errorf("expected token of the current context user, got %s", got)
+	}
+	if got := data.ClientCertificate.ValueString(); got != "" {
+		t.Errorf("expected no client certificate, got %s", got)
+	}
+}
+
+func TestClusterResourceCreateKubeconfigPath(t *testing.T) {
+	runner := (&fakeK3dRunner{}).
+		On("", nil, "cluster", "create", "test").
+		On(testKubeconfig, nil, "kubeconfig", "get", "test").
+		On("", nil, "kubeconfig", "merge", "test")
+	r := &ClusterResource{runner: runner}
+
+	kubeconfigPath := filepath.Join(t.TempDir(), ".kube", "test.yaml")
+	req := fwresource.CreateRequest{Plan: newTestPlan(t, testResourceSchema(t, &ClusterResource{}), ClusterResourceModel{
+		Name:                   types.StringValue("test"),
+		K3dConfig:              types.StringValue("apiVersion: k3d.io/v1alpha4\nkind: Simple\n"),
+		KubeconfigPath:         types.StringValue(kubeconfigPath),
+		MergeDefaultKubeconfig: types.BoolValue(true),
+		SwitchContext:          types.BoolValue(true),
+	})}
+	resp := &fwresource.CreateResponse{State: newTestState(t, testResourceSchema(t, &ClusterResource{}), nil)}
+	r.Create(context.Background(), req, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+	info, err := os.Stat(kubeconfigPath)
+	if err != nil {
+		t.Fatalf("expected kubeconfig file to be written: %v", err)
+	}
+	if got := info.Mode().Perm(); got != 0600 {
+		t.Errorf("expected kubeconfig file permissions 0600, got %o", got)
+	}
+	if kubeconfigFileChanged(kubeconfigPath, kubeconfigChecksum(testKubeconfig)) {
+		t.Error("expected kubeconfig file to hold the kubeconfig")
+	}
+	if !runner.Called("kubeconfig", "merge", "test", "--kubeconfig-merge-default", "--kubeconfig-switch-context=true") {
+		t.Errorf("expected kubeconfig merge switching the context, got %v", runner.calls)
+	}
+	if got := getTestModel[ClusterResourceModel](t, resp.State).KubeconfigFileChecksum.ValueString(); got != kubeconfigChecksum(testKubeconfig) {
+		t.Errorf("expected checksum of the written kubeconfig, got %s", got)
+	}
+}
+
+func TestClusterResourceReadKubeconfigFileChecksum(t *testing.T) {
+	runner := (&fakeK3dRunner{}).
+		On(testClusterList, nil, "cluster", "list").
+		On(testKubeconfig, nil, "kubeconfig", "get", "test")
+	r := &ClusterResource{runner: runner}
+
+	kubeconfigPath := filepath.Join(t.TempDir(), "test.yaml")
+	if err := os.WriteFile(kubeconfigPath, []byte("edited"), 0600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	state := newTestState(t, testResourceSchema(t, &ClusterResource{}), &ClusterResourceModel{
+		ID:                     types.StringValue("test"),
+		Name:                   types.StringValue("test"),
+		K3dConfig:              types.StringValue("apiVersion: k3d.io/v1alpha4\nkind: Simple\n"),
+		KubeconfigPath:         types.StringValue(kubeconfigPath),
+		KubeconfigFileChecksum: types.StringValue("sha256:previous"),
+	})
+	resp := &fwresource.ReadResponse{State: state}
+	r.Read(context.Background(), fwresource.ReadRequest{State: state}, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+	// The edited file is detected while planning, the state keeps the path.
+	data := getTestModel[ClusterResourceModel](t, resp.State)
+	if got := data.KubeconfigPath.ValueString(); got != kubeconfigPath {
+		t.Errorf("expected kubeconfig_path to be kept, got %s", got)
+	}
+	if got := data.KubeconfigFileChecksum.ValueString(); got != kubeconfigChecksum(testKubeconfig) {
+		t.Errorf("expected checksum of the kubeconfig read from k3d, got %s", got)
+	}
+}
+
+func TestClusterResourceCreateWithoutCredentials(t *testing.T) {
+	runner := (&fakeK3dRunner{}).
+		On("", nil, "cluster", "create", "test").
+		On(testKubeconfig, nil, "kubeconfig", "get", "test")
+	r := &ClusterResource{runner: runner}
+
+	kubeconfigPath := filepath.Join(t.TempDir(), "test.yaml")
+	req := fwresource.CreateRequest{Plan: newTestPlan(t, testResourceSchema(t, &ClusterResource{}), ClusterResourceModel{
+		Name:             types.StringValue("test"),
+		K3dConfig:        types.StringValue("apiVersion: k3d.io/v1alpha4\nkind: Simple\n"),
+		KubeconfigPath:   types.StringValue(kubeconfigPath),
+		StoreCredentials: types.BoolValue(false),
+	})}
+	resp := &fwresource.CreateResponse{State: newTestState(t, testResourceSchema(t, &ClusterResource{}), nil)}
+	r.Create(context.Background(), req, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+	data := getTestModel[ClusterResourceModel](t, resp.State)
+	if !data.Kubeconfig.IsNull() || !data.ClientKey.IsNull() || !data.ClientCertificate.IsNull() || !data.Token.IsNull() {
+		t.Errorf("expected credentials not to be stored, got %v", data)
+	}
+	if got := data.Host.ValueString(); got != "https://0.0.0.0:40123" {
+		t.Errorf("expected host https://0.0.0.0:40123, got %s", got)
+	}
+	if got := data.ContextName.ValueString(); got != "k3d-test" {
+		t.Errorf("expected context_name k3d-test, got %s", got)
+	}
+	// The kubeconfig file is written from the credentials read from k3d.
+	if kubeconfigFileChanged(kubeconfigPath, kubeconfigChecksum(testKubeconfig)) {
+		t.Error("expected kubeconfig file to hold the kubeconfig")
+	}
+}
+
+func TestClusterResourceReadWithoutCredentials(t *testing.T) {
+	runner := (&fakeK3dRunner{}).
+		On(testClusterList, nil, "cluster", "list").
+		On(testKubeconfig, nil, "kubeconfig", "get", "test")
+	r := &ClusterResource{runner: runner}
+
+	kubeconfigPath := filepath.Join(t.TempDir(), "test.yaml")
+	if err := writeKubeconfigFile(kubeconfigPath, testKubeconfig); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	state := newTestState(t, testResourceSchema(t, &ClusterResource{}), &ClusterResourceModel{
+		ID:               types.StringValue("test"),
+		Name:             types.StringValue("test"),
+		K3dConfig:        types.StringValue("apiVersion: k3d.io/v1alpha4\nkind: Simple\n"),
+		KubeconfigPath:   types.StringValue(kubeconfigPath),
+		StoreCredentials: types.BoolValue(false),
+	})
+	resp := &fwresource.ReadResponse{State: state}
+	r.Read(context.Background(), fwresource.ReadRequest{State: state}, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+	data := getTestModel[ClusterResourceModel](t, resp.State)
+	if !data.Kubeconfig.IsNull() || !data.ClientKey.IsNull() {
+		t.Errorf("expected credentials not to be stored, got %v", data)
+	}
+	if got := data.Host.ValueString(); got != "https://0.0.0.0:40123" {
+		t.Errorf("expected host https://0.0.0.0:40123, got %s", got)
+	}
+	if data.KubeconfigPath.IsNull() {
+		t.Error("expected current kubeconfig file not to be reported as drift")
+	}
+}
+
+func TestClusterResourceUpdateKubeconfigPath(t *testing.T) {
+	runner := (&fakeK3dRunner{}).
+		On(testKubeconfig, nil, "kubeconfig", "get", "test")
+	r := &ClusterResource{runner: runner}
+
+	dir := t.TempDir()
+	oldPath, newPath := filepath.Join(dir, "old.yaml"), filepath.Join(dir, "new.yaml")
+	if err := os.WriteFile(oldPath, []byte(testKubeconfig), 0600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	state := newTestState(t, testResourceSchema(t, &ClusterResource{}), &ClusterResourceModel{
+		ID:             types.StringValue("test"),
+		Name:           types.StringValue("test"),
+		K3dConfig:      types.StringValue("apiVersion: k3d.io/v1alpha4\nkind: Simple\n"),
+		KubeconfigPath: types.StringValue(oldPath),
+		Running:        types.BoolValue(true),
+	})
+	req := fwresource.UpdateRequest{State: state, Plan: newTestPlan(t, testResourceSchema(t, &ClusterResource{}), ClusterResourceModel{
+		ID:             types.StringValue("test"),
+		Name:           types.StringValue("test"),
+		K3dConfig:      types.StringValue("apiVersion: k3d.io/v1alpha4\nkind: Simple\n"),
+		KubeconfigPath: types.StringValue(newPath),
+		Running:        types.BoolValue(true),
+	})}
+	resp := &fwresource.UpdateResponse{State: state}
+	r.Update(context.Background(), req, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+	if _, err := os.Stat(oldPath); !os.IsNotExist(err) {
+		t.Errorf("expected previous kubeconfig file to be removed, got %v", err)
+	}
+	if kubeconfigFileChanged(newPath, kubeconfigChecksum(testKubeconfig)) {
+		t.Error("expected kubeconfig file to be written to the new path")
+	}
+}
+
+func TestClusterResourceDeleteKubeconfigPath(t *testing.T) {
+	runner := (&fakeK3dRunner{}).
+		On(testClusterList, nil, "cluster", "list").
+		On("", nil, "cluster", "delete", "test").
+		On("", nil, "node", "list")
+	r := &ClusterResource{runner: runner}
+
+	kubeconfigPath := filepath.Join(t.TempDir(), "test.yaml")
+	if err := os.WriteFile(kubeconfigPath, []byte(testKubeconfig), 0600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	state := newTestState(t, testResourceSchema(t, &ClusterResource{}), &ClusterResourceModel{
+		ID:             types.StringValue("test"),
+		Name:           types.StringValue("test"),
+		K3dConfig:      types.StringValue("apiVersion: k3d.io/v1alpha4\nkind: Simple\n"),
+		KubeconfigPath: types.StringValue(kubeconfigPath),
+	})
+	resp := &fwresource.DeleteResponse{State: state}
+	r.Delete(context.Background(), fwresource.DeleteRequest{State: state}, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+	if _, err := os.Stat(kubeconfigPath); !os.IsNotExist(err) {
+		t.Errorf("expected kubeconfig file to be removed, got %v", err)
+	}
+}
+
+func TestClusterResourceUpdateMergeDisabled(t *testing.T) {
+	runner := (&fakeK3dRunner{}).
+		On(testKubeconfig, nil, "kubeconfig", "get", "test")
+	r := &ClusterResource{runner: runner}
+
+	defaultPath := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(defaultPath, []byte(testKubeconfigMerged), 0600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Setenv("KUBECONFIG", defaultPath)
+	state := newTestState(t, testResourceSchema(t, &ClusterResource{}), &ClusterResourceModel{
+		ID:                     types.StringValue("test"),
+		Name:                   types.StringValue("test"),
+		K3dConfig:              types.StringValue("apiVersion: k3d.io/v1alpha4\nkind: Simple\n"),
+		MergeDefaultKubeconfig: types.BoolValue(true),
+		Running:                types.BoolValue(true),
+	})
+	req := fwresource.UpdateRequest{State: state, Plan: newTestPlan(t, testResourceSchema(t, &ClusterResource{}), ClusterResourceModel{
+		ID:                     types.StringValue("test"),
+		Name:                   types.StringValue("test"),
+		K3dConfig:              types.StringValue("apiVersion: k3d.io/v1alpha4\nkind: Simple\n"),
+		MergeDefaultKubeconfig: types.BoolValue(false),
+		Running:                types.BoolValue(true),
+	})}
+	resp := &fwresource.UpdateResponse{State: state}
+	r.Update(context.Background(), req, resp)
+
+	if resp.Diagnostics.HasError() || resp.Diagnostics.WarningsCount() > 0 {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+	}
+	if runner.Called("kubeconfig", "merge") {
+		t.Errorf("expected no kubeconfig merge, got %v", runner.calls)
+	}
+	content, err := os.ReadFile(defaultPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(string(content), "k3d-test") {
+		t.Errorf("expected cluster to be removed from the default kubeconfig, got:\n%s", content)
+	}
+	if !strings.Contains(string(content), "k3d-other") {
+		t.Errorf("expected other clusters to be kept in the default kubeconfig, got:\n%s", content)
+	}
+}
+
+func TestRemoveKubeconfigClusterMissing(t *testing.T) {
+	if err := removeKubeconfigCluster(filepath.Join(t.TempDir(), "config"), "test"); err != nil {
+		t.Errorf("expected missing kubeconfig to be ignored, got %v", err)
+	}
+}
+
+func TestDefaultKubeconfigPath(t *testing.T) {
+	dir := t.TempDir()
+	first, second := filepath.Join(dir, "first"), filepath.Join(dir, "second")
+	if err := os.WriteFile(second, nil, 0600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cases := []struct {
+		env  string
+		want string
+	}{
+		{first + string(filepath.ListSeparator) + second, second},
+		{first, first},
+	}
+	for _, c := range cases {
+		got, err := defaultKubeconfigPath(c.env)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != c.want {
+			t.Errorf("expected %s for KUBECONFIG %q, got %s", c.want, c.env, got)
+		}
+	}
+}
+
+// testKubeconfigMerged is a default kubeconfig with the clusters test and
+// other merged by k3d.
+const testKubeconfigMerged = `apiVersion: v1
+clusters:
+- cluster:
+    server: https://0.0.0.0:40123
+  name: k3d-test
+- cluster:
+    server: https://0.0.0.0:40124
+  name: k3d-other
+contexts:
+- context:
+    cluster: k3d-test
+    user: admin@k3d-test
+  name: k3d-test
+- context:
+    cluster: k3d-other
+    user: admin@k3d-other
+  name: k3d-other
+current-context: k3d-test
+kind: Config
+users:
+- name: admin@k3d-test
+  user:
+    token: secret
+- name: admin@k3d-other
+  user:
+    token: other
+`
+
+func TestOverrideAPIServer(t *testing.T) {
+	cases := []struct {
+		apiHost string
+		want    string
+		wantErr bool
+	}{
+		{"host.docker.internal", "https://host.docker.internal:40123", false},
+		{"192.168.1.10:6443", "https://192.168.1.10:6443", false},
+		{"::1", "https://[::1]:40123", false},
+		{"[::1]:6443", "https://[::1]:6443", false},
+		{"https://example.com", "", true},
+		{"example.com:port", "", true},
+	}
+	for _, c := range cases {
+		t.Run(c.apiHost, func(t *testing.T) {
+			got, err := overrideAPIServer("https://0.0.0.0:40123", c.apiHost)
+			if (err != nil) != c.wantErr {
+				t.Fatalf("expected error %t, got %v", c.wantErr, err)
+			}
+			if got != c.want {
+				t.Errorf("expected %s, got %s", c.want, got)
+			}
+		})
+	}
+}
+
+func TestOverrideKubeconfigServer(t *testing.T) {
+	got, err := overrideKubeconfigServer(testKubeconfigMultiple, "k3d-test", "host.docker.internal")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var kubeconfig Kubeconfig
+	if err := yaml.Unmarshal([]byte(got), &kubeconfig); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Only the server of the given cluster is overridden.
+	if server := kubeconfig.Clusters[0].Cluster.Server; server != "https://0.0.0.0:40123" {
+		t.Errorf("expected other cluster to keep its server, got %s", server)
+	}
+	if server := kubeconfig.Clusters[1].Cluster.Server; server != "https://host.docker.internal:40124" {
+		t.Errorf("expected overridden server, got %s", server)
+	}
+}
+
+func TestClusterResourceCreateAPIHostOverride(t *testing.T) {
+	runner := (&fakeK3dRunner{}).
+		On("", nil, "cluster", "create", "test").
+		On(testKubeconfig, nil, "kubeconfig", "get", "test")
+	r := &ClusterResource{runner: runner}
+
+	req := fwresource.CreateRequest{Plan: newTestPlan(t, testResourceSchema(t, &ClusterResource{}), ClusterResourceModel{
+		Name:            types.StringValue("test"),
+		K3dConfig:       types.StringValue("apiVersion: k3d.io/v1alpha4\nkind: Simple\n"),
+		APIHostOverride: types.StringValue("host.docker.internal"),
+	})}
+	resp := &fwresource.CreateResponse{State: newTestState(t, testResourceSchema(t, &ClusterResource{}), nil)}
+	r.Create(context.Background(), req, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+	if len(runner.calls) == 0 || !hasArgsPrefix(runner.calls[0][5:], []string{"--k3s-arg", "--tls-san=host.docker.internal@server:*"}) {
+		t.Errorf("expected the host to be added to the API server certificate, got %v", runner.calls)
+	}
+	data := getTestModel[ClusterResourceModel](t, resp.State)
+	if got := data.Host.ValueString(); got != "https://host.docker.internal:40123" {
+		t.Errorf("expected overridden host, got %s", got)
+	}
+	if !strings.Contains(data.Kubeconfig.ValueString(), "server: https://host.docker.internal:40123") {
+		t.Errorf("expected overridden server in kubeconfig, got %s", data.Kubeconfig.ValueString())
+	}
+}
@@ -2,17 +2,18 @@ package provider
 
 import (
 	"context"
-	"crypto/md5"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"net/http"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
@@ -22,6 +23,10 @@ import (
 
 // Ensure provider defined types fully satisfy framework interfaces
 var _ resource.Resource = &ClusterResource{}
+var _ resource.ResourceWithImportState = &ClusterResource{}
+var _ resource.ResourceWithModifyPlan = &ClusterResource{}
+var _ resource.ResourceWithValidateConfig = &ClusterResource{}
+var _ resource.ResourceWithUpgradeState = &ClusterResource{}
 
 func NewClusterResource() resource.Resource {
 	return &ClusterResource{}
@@ -29,19 +34,36 @@ func NewClusterResource() resource.Resource {
 
 // ClusterResource defines the resource implementation.
 type ClusterResource struct {
-	client *http.Client
+	runner K3dRunner
+	docker DockerRunner
 }
 
 // ClusterResourceModel describes the resource data model.
 type ClusterResourceModel struct {
-	ID                   types.String `tfsdk:"id"`
-	Name                 types.String `tfsdk:"name"`
-	K3dConfig            types.String `tfsdk:"k3d_config"`
-	Kubeconfig           types.String `tfsdk:"kubeconfig"`
-	Host                 types.String `tfsdk:"host"`
-	ClientCertificate    types.String `tfsdk:"client_certificate"`
-	ClientKey            types.String `tfsdk:"client_key"`
-	ClusterCACertificate types.String `tfsdk:"cluster_ca_certificate"`
+	ID                     types.String          `tfsdk:"id"`
+	Name                   types.String          `tfsdk:"name"`
+	K3dConfig              types.String          `tfsdk:"k3d_config"`
+	Config                 *ClusterConfigModel   `tfsdk:"config"`
+	Kubeconfig             types.String          `tfsdk:"kubeconfig"`
+	KubeconfigPath         types.String          `tfsdk:"kubeconfig_path"`
+	KubeconfigFileChecksum types.String          `tfsdk:"kubeconfig_file_checksum"`
+	MergeDefaultKubeconfig types.Bool            `tfsdk:"merge_default_kubeconfig"`
+	SwitchContext          types.Bool            `tfsdk:"switch_context"`
+	APIHostOverride        types.String          `tfsdk:"api_host_override"`
+	StoreCredentials       types.Bool            `tfsdk:"store_credentials"`
+	DeletionProtection     types.Bool            `tfsdk:"deletion_protection"`
+	OnDestroy              types.String          `tfsdk:"on_destroy"`
+	ContextName            types.String          `tfsdk:"context_name"`
+	ClusterName            types.String          `tfsdk:"cluster_name"`
+	Host                   types.String          `tfsdk:"host"`
+	ClientCertificate      types.String          `tfsdk:"client_certificate"`
+	ClientKey              types.String          `tfsdk:"client_key"`
+	ClusterCACertificate   types.String          `tfsdk:"cluster_ca_certificate"`
+	Token                  types.String          `tfsdk:"token"`
+	EnsureRunning          types.Bool            `tfsdk:"ensure_running"`
+	Running                types.Bool            `tfsdk:"running"`
+	Timeouts               *ClusterTimeoutsModel `tfsdk:"timeouts"`
+	WaitFor                *ClusterWaitForModel  `tfsdk:"wait_for"`
 }
 
 func (r *ClusterResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -50,42 +72,181 @@ func (r *ClusterResource) Metadata(ctx context.Context, req resource.MetadataReq
 
 func (r *ClusterResource) GetSchema(ctx context.Context) (tfsdk.Schema, diag.Diagnostics) {
 	return tfsdk.Schema{
+		Version: 1,
+
 		// This description is used by the documentation generator and the language server.
 		MarkdownDescription: "The resource `k3d_cluster` manages k3d clusters for development.\n" +
 			"\n" +
 			"This resource can be used in conjunction with the Kubernetes and Helm providers " +
 			"to define an entire Kubernetes development environment as code.\n" +
 			"\n" +
-			"Updating cluster configuration or name is not supported by k3d. " +
-			"When changing the `name` or `k3d_config` attributes destroy the resource and apply again.",
+			"The cluster is configured either with k3d config content in `k3d_config` " +
+			"or with the structured `config` attribute, which is rendered into `k3d_config`.\n" +
+			"\n" +
+			"Changing the amount of `agents` in `k3d_config` adds or removes agent nodes in place. " +
+			"Servers are only added in place to clusters which already have more than one server, " +
+			"since k3d starts clusters with a single server without embedded etcd, which other servers cannot join. " +
+			"Removing servers, or adding servers to a cluster with a single server, replaces the cluster. " +
+			"Updating other cluster configuration or name is not supported by k3d, " +
+			"so changing the `name` or other `k3d_config` options replaces the cluster. " +
+			"Nodes added or removed, images changed and load balancer ports changed outside of Terraform, " +
+			"such as with `k3d cluster edit`, are detected as drift and reconciled on the next apply. " +
+			"Changed ports replace the cluster. " +
+			"Ports are only compared when all ports in `k3d_config` are published on the `loadbalancer` node " +
+			"with a fixed host port, because k3d does not record the node filters of other ports, " +
+			"and random host ports and port ranges are only resolved when the cluster is created.\n" +
+			"\n" +
+			"Existing clusters can be imported by name. " +
+			"The `k3d_config` of an imported cluster is reconstructed from its nodes and includes the amount of " +
+			"servers and agents, the image and the load balancer ports. " +
+			"Make sure the configured `k3d_config` matches it to avoid replacing the cluster.",
 
 		Attributes: map[string]tfsdk.Attribute{
 			"id": {
-				MarkdownDescription: "Used internally by the provider.",
+				MarkdownDescription: "Cluster name.",
 				Type:                types.StringType,
 				Computed:            true,
+				PlanModifiers: tfsdk.AttributePlanModifiers{
+					resource.UseStateForUnknown(),
+				},
 			},
 			"name": {
-				MarkdownDescription: "Cluster name.",
+				MarkdownDescription: "Cluster name. Changing the name forces replacement.",
 				Required:            true,
 				Type:                types.StringType,
+				PlanModifiers: tfsdk.AttributePlanModifiers{
+					resource.RequiresReplace(),
+				},
 			},
 			"k3d_config": {
 				MarkdownDescription: "K3d config content. " +
 					"Use to set the amounts of servers, agents, container registries, ports, " +
 					"host aliases and more cluster related options. " +
-					"[See config options in k3d documentation](https://k3d.io/v5.4.6/usage/configfile/#config-options).",
-				Required: true,
+					"[See config options in k3d documentation](https://k3d.io/v5.4.6/usage/configfile/#config-options). " +
+					"The content is validated against the `k3d.io/v1alpha4` config schema during validate and plan. " +
+					"Changes other than the amount of `agents`, or adding `servers` to clusters with multiple servers, force replacement. " +
+					"Removing `image` or `kubeAPI` options keeps the values k3d chose, such as in the config of an imported cluster. " +
+					"Either `k3d_config` or `config` must be set, when `config` is set this is the rendered config.",
+				Optional: true,
+				Computed: true,
+				Type:     types.StringType,
+				PlanModifiers: tfsdk.AttributePlanModifiers{
+					k3dConfigRenderModifier{},
+					k3dConfigRequiresReplace(),
+				},
+			},
+			"config": clusterConfigAttribute(),
+			"api_host_override": {
+				MarkdownDescription: "Host name or IP address, with an optional port, to connect to the API server through " +
+					"instead of the address k3d writes to the kubeconfig, usually `0.0.0.0`. " +
+					"Use when the cluster is not reachable at that address, such as from dev containers, WSL, " +
+					"or with a remote Docker host. " +
+					"Overrides the server in `host`, `kubeconfig` and the file at `kubeconfig_path`, " +
+					"and adds the host to the certificate of the API server. " +
+					"The default kubeconfig merged by `merge_default_kubeconfig` keeps the address of k3d, " +
+					"set `kubeAPI.host` in `k3d_config` to change it. Changing the override forces replacement.",
+				Optional: true,
 				Type:     types.StringType,
+				PlanModifiers: tfsdk.AttributePlanModifiers{
+					resource.RequiresReplace(),
+				},
+			},
+			"store_credentials": {
+				MarkdownDescription: "Save the credentials of the cluster in the Terraform state. " +
+					"When disabled `kubeconfig`, `client_certificate`, `client_key` and `token` are not saved, " +
+					"and only the identity of the cluster, such as `host`, `context_name`, `cluster_name` " +
+					"and `cluster_ca_certificate`, stays in the state. " +
+					"Read the credentials on demand with the `k3d_kubeconfig` data source instead. " +
+					"`kubeconfig_path` and `wait_for` keep working with credentials read from k3d. " +
+					"Defaults to `true`.",
+				Optional: true,
+				Type:     types.BoolType,
+			},
+			"deletion_protection": {
+				MarkdownDescription: "Prevent destroying or replacing the cluster. " +
+					"Destroy fails with an error while enabled, and changes replacing the cluster are rejected during plan, " +
+					"set it to `false` and apply before destroying or replacing the cluster. " +
+					"Defaults to `false`.",
+				Optional: true,
+				Type:     types.BoolType,
+			},
+			"on_destroy": {
+				MarkdownDescription: "What to do with the cluster when the resource is destroyed, either `delete` or `stop`. " +
+					"`stop` runs `k3d cluster stop` instead of deleting the cluster, keeping its volumes and data, " +
+					"so it can be imported again later with `terraform import`, " +
+					"and removes the cluster from the default kubeconfig when `merge_default_kubeconfig` is enabled. " +
+					"Changes replacing the cluster are rejected while `stop` is set, " +
+					"because the stopped cluster keeps its name and the replacement could not be created. " +
+					"Defaults to `delete`.",
+				Optional: true,
+				Type:     types.StringType,
+			},
+			"ensure_running": {
+				MarkdownDescription: "Start the cluster when it is stopped, for example after a reboot. " +
+					"When enabled a stopped cluster is shown as a change in the plan and started with " +
+					"`k3d cluster start` on apply. Defaults to `false`.",
+				Optional: true,
+				Type:     types.BoolType,
+			},
+			"running": {
+				MarkdownDescription: "Whether all server nodes of the cluster are running.",
+				Type:                types.BoolType,
+				Computed:            true,
+				PlanModifiers: tfsdk.AttributePlanModifiers{
+					ensureRunningModifier{},
+				},
 			},
 			"kubeconfig": {
 				MarkdownDescription: "Kubeconfig content. " +
-					"Dump in a file and point the `KUBECONFIG` environment variable or `--kubeconfig` " +
+					"Write it to a file with `kubeconfig_path` and point the `KUBECONFIG` environment variable or `--kubeconfig` " +
 					"flag at it to use kubectl or Helm with the cluster.",
 				Type:      types.StringType,
 				Computed:  true,
 				Sensitive: true,
 			},
+			"kubeconfig_path": {
+				MarkdownDescription: "Path of a file to write the kubeconfig to, readable only by the current user. " +
+					"Use `pathexpand` for paths in the home directory. " +
+					"The file is written again when it was changed or removed, and removed when the cluster is destroyed.",
+				Optional: true,
+				Type:     types.StringType,
+			},
+			"kubeconfig_file_checksum": {
+				MarkdownDescription: "SHA-256 checksum of the kubeconfig written to `kubeconfig_path`. " +
+					"Plans show a change of the checksum when the file no longer has it, " +
+					"such as when it was changed or removed outside of Terraform, and applying writes the file again.",
+				Type:     types.StringType,
+				Computed: true,
+				PlanModifiers: tfsdk.AttributePlanModifiers{
+					kubeconfigFileModifier{},
+				},
+			},
+			"merge_default_kubeconfig": {
+				MarkdownDescription: "Merge the kubeconfig into the default kubeconfig, " +
+					"the file in the `KUBECONFIG` environment variable or `~/.kube/config`, " +
+					"the way `k3d kubeconfig merge --kubeconfig-merge-default` does. " +
+					"k3d removes the cluster from the default kubeconfig when it deletes the cluster, " +
+					"and the provider removes it when this option is disabled. " +
+					"Defaults to `false`.",
+				Optional: true,
+				Type:     types.BoolType,
+			},
+			"switch_context": {
+				MarkdownDescription: "Switch the current context of the default kubeconfig to the cluster " +
+					"when merging the kubeconfig. Requires `merge_default_kubeconfig`. Defaults to `false`.",
+				Optional: true,
+				Type:     types.BoolType,
+			},
+			"context_name": {
+				MarkdownDescription: "Name of the current context of the kubeconfig, such as `k3d-example`.",
+				Type:                types.StringType,
+				Computed:            true,
+			},
+			"cluster_name": {
+				MarkdownDescription: "Name of the cluster of the current context in the kubeconfig, such as `k3d-example`.",
+				Type:                types.StringType,
+				Computed:            true,
+			},
 			"host": {
 				MarkdownDescription: "Cluster host. " +
 					"Use to authenticate other providers with the cluster. " +
@@ -125,28 +286,84 @@ func (r *ClusterResource) GetSchema(ctx context.Context) (tfsdk.Schema, diag.Dia
 				Computed:  true,
 				Sensitive: true,
 			},
+			"token": {
+				MarkdownDescription: "Bearer token of the kubeconfig user. " +
+					"Empty when the user authenticates with a client certificate. " +
+					"Users authenticating with an exec plugin have neither a token nor a client certificate, " +
+					"use `kubeconfig` for them instead.",
+				Type:      types.StringType,
+				Computed:  true,
+				Sensitive: true,
+			},
+		},
+		Blocks: map[string]tfsdk.Block{
+			"timeouts": clusterTimeoutsBlock(),
+			"wait_for": clusterWaitForBlock(),
 		},
 	}, nil
 }
 
+func (r *ClusterResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var k3dConfig types.String
+	var config types.Object
+
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("k3d_config"), &k3dConfig)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("config"), &config)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !k3dConfig.IsNull() && !config.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("config"),
+			"Conflicting cluster configuration",
+			"Set either `k3d_config` or `config`, not both.")
+	}
+	if k3dConfig.IsNull() && config.IsNull() {
+		resp.Diagnostics.AddError(
+			"Missing cluster configuration",
+			"Set either `k3d_config` or `config` to configure the cluster.")
+	}
+
+	resp.Diagnostics.Append(validateClusterTimeouts(ctx, req.Config)...)
+	resp.Diagnostics.Append(validateClusterWaitFor(ctx, req.Config)...)
+	resp.Diagnostics.Append(validateClusterKubeconfig(ctx, req.Config)...)
+	resp.Diagnostics.Append(validateClusterAPIHost(ctx, req.Config)...)
+	resp.Diagnostics.Append(validateClusterOnDestroy(ctx, req.Config)...)
+
+	// The config is only known during validation when it does not depend on
+	// other resources.
+	if k3dConfig.IsNull() || k3dConfig.IsUnknown() {
+		return
+	}
+	for _, err := range validateK3dConfig(k3dConfig.ValueString()) {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("k3d_config"),
+			"Invalid k3d config",
+			fmt.Sprintf("%s. See config options in k3d documentation: https://k3d.io/v5.4.6/usage/configfile/#config-options", err))
+	}
+}
+
 func (r *ClusterResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
 	// Prevent panic if the provider has not been configured.
 	if req.ProviderData == nil {
 		return
 	}
 
-	client, ok := req.ProviderData.(*http.Client)
+	runner, ok := req.ProviderData.(K3dRunner)
 
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Resource Configure Type",
-			fmt.Sprintf("Expected *http.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected K3dRunner, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 
 		return
 	}
 
-	r.client = client
+	r.runner = runner
+	r.docker = newExecDockerRunner(runner)
 }
 
 func (r *ClusterResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
@@ -159,13 +376,9 @@ func (r *ClusterResource) Create(ctx context.Context, req resource.CreateRequest
 		return
 	}
 
-	// If applicable, this is a great opportunity to initialize any necessary
-	// provider client data and make a call using it.
-	// httpResp, err := d.client.Do(httpReq)
-	// if err != nil {
-	//     resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create example, got error: %s", err))
-	//     return
-	// }
+	ctx, cancel := context.WithTimeout(ctx, data.Timeouts.CreateTimeout())
+	defer cancel()
+	ctx = tflog.SetField(ctx, "name", data.Name.ValueString())
 
 	configPath := fmt.Sprintf(
 		filepath.Join(os.TempDir(), "terraform-provider-k3d-%s.yaml"),
@@ -175,8 +388,11 @@ func (r *ClusterResource) Create(ctx context.Context, req resource.CreateRequest
 		return
 	}
 
-	cmd := exec.Command("k3d", "cluster", "create", data.Name.ValueString(), "--config", configPath)
-	output, createErr := cmd.CombinedOutput()
+	args := []string{"cluster", "create", data.Name.ValueString(), "--config", configPath}
+	if !data.APIHostOverride.IsNull() {
+		args = append(args, apiHostTLSSANArgs(data.APIHostOverride.ValueString())...)
+	}
+	output, createErr := r.runner.Run(withK3dPhase(ctx, "create"), args...)
 
 	// Remove the config file even when create command failed.
 	if err := os.Remove(configPath); err != nil {
@@ -185,57 +401,92 @@ func (r *ClusterResource) Create(ctx context.Context, req resource.CreateRequest
 	}
 
 	if createErr != nil {
-		outputString := string(output)
-		if strings.Contains(outputString, "already exists") {
-			// TODO Handle name already exists.
-		}
-		if strings.Contains(outputString, "Schema Validation failed") {
-			// TODO Handle schema validation failure.
+		// The config attribute rendered the k3d config when it is set.
+		object := k3dObject{
+			Kind:       "cluster",
+			Name:       data.Name.ValueString(),
+			NamePath:   path.Root("name"),
+			ConfigPath: path.Root("k3d_config"),
 		}
-		if strings.Contains(outputString, "permission denied") {
-			// TODO Handle running without permission for Docker.
+		if data.Config != nil {
+			object.ConfigPath = path.Root("config")
 		}
-		if strings.Contains(outputString, "executable file not found") {
-			// TODO Handle k3d is not installed.
-		}
-		resp.Diagnostics.AddError("Failed creating k3d cluster", outputString)
+		addK3dError(&resp.Diagnostics, "Failed creating k3d cluster", object, output, createErr)
 		return
 	}
-	checksum := md5.Sum([]byte(data.K3dConfig.String()))
-	configChecksum := fmt.Sprintf("%x", checksum)
-	data.ID = types.StringValue(configChecksum)
+	data.ID = data.Name
 
-	cmd = exec.Command("k3d", "kubeconfig", "get", data.Name.ValueString())
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		resp.Diagnostics.AddError("Failed getting Kubeconfig from k3d", string(output))
+	data.Running = types.BoolValue(true)
+
+	resp.Diagnostics.Append(r.readKubeconfig(ctx, data)...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	var kubeconfig Kubeconfig
-	if err := yaml.Unmarshal(output, &kubeconfig); err != nil {
-		resp.Diagnostics.AddError("Failed parsing Kubeconfig", fmt.Sprint(err))
+	// Write logs using the tflog package
+	// Documentation: https://terraform.io/plugin/log
+	tflog.Trace(ctx, "created a resource")
+
+	// Save data into Terraform state, the kubeconfig file checksum is set
+	// once the file is written.
+	data.KubeconfigFileChecksum = types.StringNull()
+	resp.Diagnostics.Append(resp.State.Set(ctx, data.stateModel())...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	if len(kubeconfig.Clusters) != 1 || len(kubeconfig.Users) != 1 {
-		resp.Diagnostics.AddError(
-			"Kubeconfig parsed with more than 1 user or cluster.",
-			"contact the provider's developer")
+	// The cluster is saved before exporting the kubeconfig and waiting, so
+	// Terraform taints it instead of losing track of it when either fails.
+	resp.Diagnostics.Append(r.exportKubeconfig(ctx, data, nil)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("kubeconfig_file_checksum"), data.KubeconfigFileChecksum)...)
+	if resp.Diagnostics.HasError() || data.WaitFor == nil {
 		return
 	}
-	data.Host = types.StringValue(kubeconfig.Clusters[0].Cluster.Server)
-	data.ClusterCACertificate = types.StringValue(kubeconfig.Clusters[0].Cluster.CertificateAuthorityData)
-	data.ClientCertificate = types.StringValue(kubeconfig.Users[0].User.ClientCertificateData)
-	data.ClientKey = types.StringValue(kubeconfig.Users[0].User.ClientKeyData)
-	data.Kubeconfig = types.StringValue(string(output))
 
-	// Write logs using the tflog package
-	// Documentation: https://terraform.io/plugin/log
-	tflog.Trace(ctx, "created a resource")
+	resp.Diagnostics.Append(waitForClusterResource(ctx, data, clusterWaitInterval)...)
+}
 
-	// Save data into Terraform state
-	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+// stateModel returns the model to save in the Terraform state. The
+// credentials are left out when store_credentials is disabled, and are read
+// from k3d again when needed.
+func (m *ClusterResourceModel) stateModel() *ClusterResourceModel {
+	if m.StoreCredentials.IsNull() || m.StoreCredentials.ValueBool() {
+		return m
+	}
+	stored := *m
+	stored.Kubeconfig = types.StringNull()
+	stored.ClientCertificate = types.StringNull()
+	stored.ClientKey = types.StringNull()
+	stored.Token = types.StringNull()
+	return &stored
+}
+
+// waitForClusterResource waits until the cluster of data is ready as its
+// wait_for attribute defines.
+func waitForClusterResource(ctx context.Context, data *ClusterResourceModel, interval time.Duration) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	client, err := newKubeAPIClient(
+		data.Host.ValueString(),
+		data.ClusterCACertificate.ValueString(),
+		data.ClientCertificate.ValueString(),
+		data.ClientKey.ValueString(),
+		data.Token.ValueString())
+	if err != nil {
+		diags.AddError("Failed reading cluster credentials", fmt.Sprint(err))
+		return diags
+	}
+
+	tflog.Info(ctx, "waiting for cluster to become ready")
+	if err := waitForCluster(ctx, client, *data.WaitFor, interval); err != nil {
+		diags.AddAttributeError(
+			path.Root("wait_for"),
+			"Cluster did not become ready",
+			fmt.Sprintf("The cluster was created but did not become ready: %s. "+
+				"The cluster is marked as tainted and replaced on the next apply. "+
+				"Increase the `create` timeout when the cluster is slow to start.", err))
+	}
+	return diags
 }
 
 func (r *ClusterResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
@@ -248,23 +499,12 @@ func (r *ClusterResource) Read(ctx context.Context, req resource.ReadRequest, re
 		return
 	}
 
-	// If applicable, this is a great opportunity to initialize any necessary
-	// provider client data and make a call using it.
-	// httpResp, err := d.client.Do(httpReq)
-	// if err != nil {
-	//     resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read example, got error: %s", err))
-	//     return
-	// }
+	ctx, cancel := context.WithTimeout(ctx, data.Timeouts.ReadTimeout())
+	defer cancel()
 
-	cmd := exec.Command("k3d", "cluster", "list", "--output", "json")
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		resp.Diagnostics.AddError("Failed listing k3d cluster", fmt.Sprint(err))
-		return
-	}
-	var clusters []K3dClusterInfo
-	if err := json.Unmarshal(output, &clusters); err != nil {
-		fmt.Println(err)
+	clusters, diags := listClusters(ctx, r.runner)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 	cluster, err := findCluster(clusters, data.Name.ValueString())
@@ -272,37 +512,164 @@ func (r *ClusterResource) Read(ctx context.Context, req resource.ReadRequest, re
 		resp.State.RemoveResource(ctx)
 		return
 	}
-	if cluster.ServersRunning < cluster.ServersCount {
-		// TODO handle needing to start the cluster?
+	// A stopped cluster is reported as drift when ensure_running is set.
+	data.Running = types.BoolValue(cluster.Running())
+
+	// Nodes added or removed outside of Terraform are reported as drift, which
+	// is reconciled by scaling the cluster on the next apply.
+	if !data.K3dConfig.IsNull() && !data.K3dConfig.IsUnknown() {
+		k3dConfig, drifted, err := reconcileK3dConfig(data.K3dConfig.ValueString(), cluster)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("k3d_config"), "Failed reading k3d config", err.Error())
+			return
+		}
+		if drifted {
+			tflog.Info(ctx, "k3d cluster differs from k3d_config", map[string]interface{}{
+				"name": data.Name.ValueString(),
+			})
+			data.K3dConfig = types.StringValue(k3dConfig)
+		}
 	}
 
-	cmd = exec.Command("k3d", "kubeconfig", "get", data.Name.ValueString())
-	output, err = cmd.CombinedOutput()
+	// Keep the last known credentials of a stopped cluster until it is started.
+	if data.Running.ValueBool() {
+		resp.Diagnostics.Append(r.readKubeconfig(ctx, data)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	// The kubeconfig file is compared with the checksum while planning, so it
+	// is written again when k3d returns a different kubeconfig. The checksum
+	// is kept without credentials, such as for stopped clusters which do not
+	// store them.
+	if !data.KubeconfigPath.IsNull() && !data.Kubeconfig.IsNull() {
+		data.KubeconfigFileChecksum = types.StringValue(kubeconfigChecksum(data.Kubeconfig.ValueString()))
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, data.stateModel())...)
+}
+
+// listClusters lists the k3d clusters.
+func listClusters(ctx context.Context, runner K3dRunner) ([]K3dClusterInfo, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	output, err := runner.Run(ctx, "cluster", "list", "--output", "json")
 	if err != nil {
-		resp.Diagnostics.AddError("Failed getting Kubeconfig from k3d", string(output))
-		return
+		addK3dError(&diags, "Failed listing k3d cluster", k3dObject{}, output, err)
+		return nil, diags
+	}
+	var clusters []K3dClusterInfo
+	if err := json.Unmarshal(output, &clusters); err != nil {
+		diags.AddError("Failed parsing k3d cluster list", fmt.Sprint(err))
+		return nil, diags
+	}
+	return clusters, diags
+}
+
+// scaleNodes creates or deletes nodes with the given role until the cluster
+// has the desired amount of them. New nodes use the image of the existing
+// cluster nodes.
+func (r *ClusterResource) scaleNodes(ctx context.Context, cluster K3dClusterInfo, role string, desired int) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	nodes := cluster.NodesWithRole(role)
+
+	// Nodes deleted outside of Terraform leave gaps in the names, so new
+	// nodes take the first index neither a node created with the cluster nor
+	// a node created by scaling uses.
+	containers := map[string]bool{}
+	for _, node := range cluster.Nodes {
+		containers[node.Name] = true
+	}
+	index := 0
+	for created := len(nodes); created < desired; created++ {
+		nodeName := fmt.Sprintf("%s-%s-%d", cluster.Name, role, index)
+		for containers["k3d-"+nodeName] || containers[nodeContainerName(nodeName)] {
+			index++
+			nodeName = fmt.Sprintf("%s-%s-%d", cluster.Name, role, index)
+		}
+		index++
+		args := []string{"node", "create", nodeName, "--cluster", cluster.Name, "--role", role, "--wait"}
+		if image := cluster.Image(); image != "" {
+			args = append(args, "--image", image)
+		}
+		output, err := r.runner.Run(withK3dPhase(ctx, "scale"), args...)
+		if err != nil {
+			addK3dError(&diags, fmt.Sprintf("Failed creating k3d %s node", role), k3dObject{Kind: "node", Name: nodeContainerName(nodeName)}, output, err)
+			return diags
+		}
+		tflog.Info(ctx, "created node", map[string]interface{}{"name": cluster.Name, "role": role})
+	}
+
+	// Delete the most recently created nodes first.
+	for i := len(nodes) - 1; i >= desired; i-- {
+		output, err := r.runner.Run(withK3dPhase(ctx, "scale"), "node", "delete", nodes[i].Name)
+		if err != nil {
+			addK3dError(&diags, fmt.Sprintf("Failed deleting k3d %s node", role), k3dObject{Kind: "node", Name: nodes[i].Name}, output, err)
+			return diags
+		}
+		tflog.Info(ctx, "deleted node", map[string]interface{}{"name": cluster.Name, "node": nodes[i].Name})
+	}
+	return diags
+}
+
+// readKubeconfig gets the cluster kubeconfig from k3d and sets the attributes
+// derived from it on data.
+func (r *ClusterResource) readKubeconfig(ctx context.Context, data *ClusterResourceModel) diag.Diagnostics {
+	credentials, content, diags := getKubeconfig(ctx, r.runner, data.Name.ValueString())
+	if diags.HasError() {
+		return diags
+	}
+
+	// Clients outside of the Docker host reach the API server through the
+	// override instead of the address k3d writes.
+	if !data.APIHostOverride.IsNull() {
+		var err error
+		content, err = applyAPIHostOverride(&credentials, content, data.APIHostOverride.ValueString())
+		if err != nil {
+			diags.AddAttributeError(path.Root("api_host_override"), "Failed overriding API host", fmt.Sprint(err))
+			return diags
+		}
 	}
 
+	data.ContextName = types.StringValue(credentials.ContextName)
+	data.ClusterName = types.StringValue(credentials.ClusterName)
+	data.Host = types.StringValue(credentials.Cluster.Server)
+	data.ClusterCACertificate = types.StringValue(credentials.Cluster.CertificateAuthorityData)
+	data.ClientCertificate = types.StringValue(credentials.User.ClientCertificateData)
+	data.ClientKey = types.StringValue(credentials.User.ClientKeyData)
+	data.Token = types.StringValue(credentials.User.Token)
+	data.Kubeconfig = types.StringValue(content)
+	return diags
+}
+
+// getKubeconfig gets the kubeconfig of the cluster called name from k3d and
+// returns the credentials of its current context and its content.
+func getKubeconfig(ctx context.Context, runner K3dRunner, name string) (KubeconfigCredentials, string, diag.Diagnostics) {
+	var diags diag.Diagnostics
 	var kubeconfig Kubeconfig
-	if err := yaml.Unmarshal(output, &kubeconfig); err != nil {
-		resp.Diagnostics.AddError("Failed parsing Kubeconfig", fmt.Sprint(err))
-		return
+
+	output, err := runner.Run(ctx, "kubeconfig", "get", name)
+	if err != nil {
+		addK3dError(&diags, "Failed getting Kubeconfig from k3d", k3dObject{Kind: "cluster", Name: name}, output, err)
+		return KubeconfigCredentials{}, "", diags
 	}
 
-	if len(kubeconfig.Clusters) != 1 || len(kubeconfig.Users) != 1 {
-		resp.Diagnostics.AddError(
-			"Kubeconfig parsed with more than 1 user or cluster.",
-			"contact the provider's developer")
-		return
+	if err := yaml.Unmarshal(output, &kubeconfig); err != nil {
+		diags.AddError("Failed parsing Kubeconfig", fmt.Sprint(err))
+		return KubeconfigCredentials{}, "", diags
 	}
-	data.Host = types.StringValue(kubeconfig.Clusters[0].Cluster.Server)
-	data.ClusterCACertificate = types.StringValue(kubeconfig.Clusters[0].Cluster.CertificateAuthorityData)
-	data.ClientCertificate = types.StringValue(kubeconfig.Users[0].User.ClientCertificateData)
-	data.ClientKey = types.StringValue(kubeconfig.Users[0].User.ClientKeyData)
-	data.Kubeconfig = types.StringValue(string(output))
 
-	// Save updated data into Terraform state
-	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+	credentials, err := kubeconfig.Credentials()
+	if err != nil {
+		diags.AddError(
+			"Failed reading Kubeconfig credentials",
+			fmt.Sprintf("The kubeconfig of cluster %q is not supported: %s.", name, err))
+		return KubeconfigCredentials{}, "", diags
+	}
+	return credentials, string(output), diags
 }
 
 func findCluster(clusters []K3dClusterInfo, name string) (K3dClusterInfo, error) {
@@ -315,34 +682,246 @@ func findCluster(clusters []K3dClusterInfo, name string) (K3dClusterInfo, error)
 }
 
 type K3dClusterInfo struct {
-	Name           string `json:"name"`
-	ServersCount   int    `json:"serversCount"`
-	ServersRunning int    `json:"serversRunning"`
+	Name            string                `json:"name"`
+	Network         K3dClusterNetworkInfo `json:"network"`
+	ServersCount    int                   `json:"serversCount"`
+	ServersRunning  int                   `json:"serversRunning"`
+	AgentsCount     int                   `json:"agentsCount"`
+	AgentsRunning   int                   `json:"agentsRunning"`
+	HasLoadbalancer bool                  `json:"hasLoadbalancer"`
+	ImageVolume     string                `json:"imageVolume"`
+	Nodes           []K3dNodeInfo         `json:"nodes"`
+}
+
+type K3dClusterNetworkInfo struct {
+	Name     string `json:"name"`
+	ID       string `json:"id"`
+	External bool   `json:"external"`
+}
+
+type K3dNodeInfo struct {
+	Name          string                      `json:"name"`
+	Role          string                      `json:"role"`
+	Image         string                      `json:"image"`
+	Created       string                      `json:"created"`
+	PortMappings  map[string][]K3dPortBinding `json:"portMappings"`
+	Env           []string                    `json:"env"`
+	RuntimeLabels map[string]string           `json:"runtimeLabels"`
+	State         K3dNodeState                `json:"state"`
+}
+
+type K3dNodeState struct {
+	Running bool   `json:"running"`
+	Status  string `json:"status"`
+}
+
+type K3dPortBinding struct {
+	HostIP   string `json:"HostIp"`
+	HostPort string `json:"HostPort"`
+}
+
+// Running reports whether all server nodes of the cluster are running.
+func (c K3dClusterInfo) Running() bool {
+	return c.ServersCount > 0 && c.ServersRunning == c.ServersCount
+}
+
+// NodesWithRole returns the cluster nodes with the given role in order of
+// creation, without the nodes managed by k3d_node resources.
+func (c K3dClusterInfo) NodesWithRole(role string) []K3dNodeInfo {
+	var nodes []K3dNodeInfo
+	for _, node := range c.Nodes {
+		if node.Role == role && node.RuntimeLabels[nodeResourceLabel] == "" {
+			nodes = append(nodes, node)
+		}
+	}
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return nodes[i].Created < nodes[j].Created
+	})
+	return nodes
+}
+
+// NodeCount returns the number of cluster nodes with the given role, without
+// the nodes managed by k3d_node resources.
+func (c K3dClusterInfo) NodeCount(role string) int {
+	count := c.AgentsCount
+	if role == "server" {
+		count = c.ServersCount
+	}
+	for _, node := range c.Nodes {
+		if node.Role == role && node.RuntimeLabels[nodeResourceLabel] != "" {
+			count--
+		}
+	}
+	return count
+}
+
+// Image returns the k3s image of the cluster server nodes.
+func (c K3dClusterInfo) Image() string {
+	for _, node := range c.NodesWithRole("server") {
+		if node.Image != "" {
+			return node.Image
+		}
+	}
+	return ""
+}
+
+// InstanceID returns a short checksum identifying this instance of the
+// cluster. It changes when the cluster is deleted and created again under the
+// same name, because the network and the first server are created again.
+func (c K3dClusterInfo) InstanceID() string {
+	var created string
+	if servers := c.NodesWithRole("server"); len(servers) > 0 {
+		created = servers[0].Created
+	}
+	return shortChecksum(c.Network.ID + "/" + created)
 }
 
 func (r *ClusterResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	var data *ClusterResourceModel
+	var data, state *ClusterResourceModel
 
-	// Read Terraform plan data into the model
+	// Read Terraform plan and prior state data into the models
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
 
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	// If applicable, this is a great opportunity to initialize any necessary
-	// provider client data and make a call using it.
-	// httpResp, err := d.client.Do(httpReq)
-	// if err != nil {
-	//     resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to update example, got error: %s", err))
-	//     return
-	// }
-	resp.Diagnostics.AddError(
-		"Updating clusters is not supported by k3d",
-		"Destroy the resource and apply again to recreate the cluster.")
+	ctx, cancel := context.WithTimeout(ctx, data.Timeouts.UpdateTimeout())
+	defer cancel()
+	ctx = tflog.SetField(ctx, "name", data.Name.ValueString())
+
+	if !data.K3dConfig.Equal(state.K3dConfig) {
+		resp.Diagnostics.Append(r.updateK3dConfig(ctx, data.Name.ValueString(), state.K3dConfig.ValueString(), data.K3dConfig.ValueString())...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	if data.EnsureRunning.ValueBool() && !state.Running.ValueBool() {
+		output, err := r.runner.Run(withK3dPhase(ctx, "start"), "cluster", "start", data.Name.ValueString())
+		if err != nil {
+			addK3dError(&resp.Diagnostics, "Failed starting k3d cluster", k3dObject{Kind: "cluster", Name: data.Name.ValueString()}, output, err)
+			return
+		}
+		tflog.Info(ctx, "started stopped cluster", map[string]interface{}{"name": data.Name.ValueString()})
+		state.Running = types.BoolValue(true)
+	}
+
+	data.ID = state.ID
+	data.Running = state.Running
+
+	resp.Diagnostics.Append(r.readKubeconfig(ctx, data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.exportKubeconfig(ctx, data, state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
 	// Save updated data into Terraform state
-	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+	resp.Diagnostics.Append(resp.State.Set(ctx, data.stateModel())...)
+}
+
+// updateK3dConfig applies a change of k3d config to a running cluster. Only
+// changes of the server and agent counts are supported by k3d.
+func (r *ClusterResource) updateK3dConfig(ctx context.Context, name string, oldConfig string, newConfig string) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	scalable, err := k3dConfigScalable(oldConfig, newConfig)
+	if err != nil {
+		diags.AddAttributeError(path.Root("k3d_config"), "Invalid k3d config", fmt.Sprint(err))
+		return diags
+	}
+	if !scalable {
+		diags.AddAttributeError(
+			path.Root("k3d_config"),
+			"Updating clusters is not supported by k3d",
+			"Only the amount of agents, and the amount of servers of clusters with multiple servers, can be changed in place, "+
+				"and servers can only be added. "+
+				"Destroy the resource and apply again to recreate the cluster.")
+		return diags
+	}
+
+	config, err := parseK3dConfig(newConfig)
+	if err != nil {
+		diags.AddAttributeError(path.Root("k3d_config"), "Invalid k3d config", fmt.Sprint(err))
+		return diags
+	}
+	counts := k3dConfigNodeCounts(config)
+
+	clusters, listDiags := listClusters(ctx, r.runner)
+	diags.Append(listDiags...)
+	if diags.HasError() {
+		return diags
+	}
+	cluster, err := findCluster(clusters, name)
+	if err != nil {
+		diags.AddError("Failed finding k3d cluster", fmt.Sprintf("Cluster %q does not exist.", name))
+		return diags
+	}
+
+	diags.Append(r.scaleNodes(ctx, cluster, "server", counts.Servers)...)
+	if diags.HasError() {
+		return diags
+	}
+	diags.Append(r.scaleNodes(ctx, cluster, "agent", counts.Agents)...)
+	return diags
+}
+
+func (r *ClusterResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Only replacing the cluster is affected by on_destroy and
+	// deletion_protection.
+	if req.State.Raw.IsNull() || req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var onDestroy types.String
+	var deletionProtection types.Bool
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("on_destroy"), &onDestroy)...)
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("deletion_protection"), &deletionProtection)...)
+	if resp.Diagnostics.HasError() || (onDestroy.ValueString() != "stop" && !deletionProtection.ValueBool()) {
+		return
+	}
+
+	var plannedName, priorName, plannedConfig, priorConfig, plannedAPIHost, priorAPIHost types.String
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("name"), &plannedName)...)
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("name"), &priorName)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("k3d_config"), &plannedConfig)...)
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("k3d_config"), &priorConfig)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("api_host_override"), &plannedAPIHost)...)
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("api_host_override"), &priorAPIHost)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The same changes the attribute plan modifiers replace the cluster for.
+	replaced := !plannedName.Equal(priorName) ||
+		!plannedAPIHost.Equal(priorAPIHost) ||
+		k3dConfigChangeRequiresReplace(priorConfig, plannedConfig)
+	if !replaced {
+		return
+	}
+
+	// Delete refuses protected clusters, so the replacement would fail after
+	// the plan is applied.
+	if deletionProtection.ValueBool() {
+		resp.Diagnostics.AddError(
+			"Cannot replace a cluster protected from deletion",
+			fmt.Sprintf("The planned changes replace cluster %q, but it has `deletion_protection` enabled. "+
+				"Set `deletion_protection` to `false` and apply before making changes that replace the cluster.", priorName.ValueString()))
+		return
+	}
+
+	// Destroying the cluster only stops it, so the replacement would fail to
+	// create a cluster with the same name.
+	resp.Diagnostics.AddError(
+		"Cannot replace a cluster stopped on destroy",
+		"The planned changes replace the cluster, but `on_destroy` is set to \"stop\", "+
+			"which keeps the stopped cluster and its name instead of deleting it. "+
+			"Set `on_destroy` to \"delete\" and apply before making changes that replace the cluster.")
 }
 
 func (r *ClusterResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
@@ -355,35 +934,174 @@ func (r *ClusterResource) Delete(ctx context.Context, req resource.DeleteRequest
 		return
 	}
 
-	// If applicable, this is a great opportunity to initialize any necessary
-	// provider client data and make a call using it.
-	// httpResp, err := d.client.Do(httpReq)
-	// if err != nil {
-	//     resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to delete example, got error: %s", err))
-	//     return
-	// }
-	cmd := exec.Command("k3d", "cluster", "delete", data.Name.ValueString())
-	if err := cmd.Run(); err != nil {
-		resp.Diagnostics.AddError("Failed deleting k3d cluster", fmt.Sprint(err))
+	ctx, cancel := context.WithTimeout(ctx, data.Timeouts.DeleteTimeout())
+	defer cancel()
+	ctx = tflog.SetField(ctx, "name", data.Name.ValueString())
+
+	if data.DeletionProtection.ValueBool() {
+		resp.Diagnostics.AddError(
+			"Cluster is protected from deletion",
+			fmt.Sprintf("Cluster %q has `deletion_protection` enabled. "+
+				"Set `deletion_protection` to `false` and apply before destroying or replacing the cluster.", data.Name.ValueString()))
+		return
+	}
+
+	clusters, diags := listClusters(ctx, r.runner)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
+
+	cluster, err := findCluster(clusters, data.Name.ValueString())
+	switch {
+	case err != nil:
+		// The cluster was deleted outside of Terraform, such as with
+		// `k3d cluster delete`, which is what destroying it would do.
+		tflog.Warn(ctx, "cluster was already deleted", map[string]interface{}{"name": data.Name.ValueString()})
+	case data.OnDestroy.ValueString() == "stop":
+		// The stopped cluster keeps its volumes and can be imported again.
+		if output, err := r.runner.Run(withK3dPhase(ctx, "stop"), "cluster", "stop", cluster.Name); err != nil {
+			addK3dError(&resp.Diagnostics, "Failed stopping k3d cluster", k3dObject{Kind: "cluster", Name: cluster.Name}, output, err)
+			return
+		}
+		tflog.Info(ctx, "stopped cluster instead of deleting it", map[string]interface{}{"name": cluster.Name})
+		// k3d keeps stopped clusters in the default kubeconfig.
+		if data.MergeDefaultKubeconfig.ValueBool() {
+			resp.Diagnostics.Append(r.removeMergedKubeconfig(ctx, cluster.Name)...)
+		}
+	default:
+		if output, err := r.runner.Run(withK3dPhase(ctx, "delete"), "cluster", "delete", cluster.Name); err != nil {
+			addK3dError(&resp.Diagnostics, "Failed deleting k3d cluster", k3dObject{Kind: "cluster", Name: cluster.Name}, output, err)
+			return
+		}
+		resp.Diagnostics.Append(r.verifyClusterDeleted(ctx, cluster)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	// k3d removes a deleted cluster from the default kubeconfig itself.
+	if !data.KubeconfigPath.IsNull() {
+		if err := removeKubeconfigFile(data.KubeconfigPath.ValueString()); err != nil {
+			resp.Diagnostics.AddWarning("Failed removing kubeconfig file", fmt.Sprint(err))
+		}
+	}
+}
+
+// verifyClusterDeleted checks that k3d removed the nodes, including the load
+// balancer, and the network of a deleted cluster. Networks which k3d did not
+// create are kept by k3d and not checked. Checks which can not run are
+// skipped with a warning, so only observed leftovers fail the deletion.
+func (r *ClusterResource) verifyClusterDeleted(ctx context.Context, cluster K3dClusterInfo) diag.Diagnostics {
+	var diags diag.Diagnostics
+	var leftovers []string
+
+	nodes, nodeDiags := listNodes(ctx, r.runner)
+	if nodeDiags.HasError() {
+		diags.AddWarning(
+			"Skipped checking for leftover k3d nodes",
+			fmt.Sprintf("k3d deleted cluster %q, but its nodes could not be listed to check that they were removed.\n\n%s",
+				cluster.Name, nodeDiags[0].Detail()))
+	}
+	for _, node := range nodes {
+		// Registries are shared between clusters and deleted separately.
+		if node.RuntimeLabels["k3d.cluster"] == cluster.Name && node.Role != "registry" {
+			leftovers = append(leftovers, fmt.Sprintf("%s node %s", node.Role, node.Name))
+		}
+	}
+
+	if cluster.Network.Name != "" && !cluster.Network.External {
+		networks, err := r.dockerNetworks(ctx)
+		if err != nil {
+			diags.AddWarning(
+				"Skipped checking for a leftover Docker network",
+				fmt.Sprintf("k3d deleted cluster %q, but the Docker networks could not be listed with the docker CLI "+
+					"to check that network %q was removed.\n\n%s", cluster.Name, cluster.Network.Name, err))
+		}
+		for _, network := range networks {
+			if network == cluster.Network.Name {
+				leftovers = append(leftovers, fmt.Sprintf("network %s", cluster.Network.Name))
+			}
+		}
+	}
+
+	if len(leftovers) > 0 {
+		diags.AddError(
+			"k3d cluster was not fully deleted",
+			fmt.Sprintf("k3d deleted cluster %q but left behind: %s. "+
+				"Remove them with `docker rm --force` and `docker network rm`, then destroy the cluster again.",
+				cluster.Name, strings.Join(leftovers, ", ")))
+	}
+	return diags
+}
+
+// dockerNetworks lists the names of the Docker networks with the docker CLI.
+func (r *ClusterResource) dockerNetworks(ctx context.Context) ([]string, error) {
+	if r.docker == nil {
+		return nil, errDockerUnavailable
+	}
+	output, err := r.docker.Run(ctx, "network", "ls", "--format", "{{.Name}}")
+	if errors.Is(err, errDockerUnavailable) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", strings.TrimSpace(string(output)), err)
+	}
+	var networks []string
+	for _, network := range strings.Split(string(output), "\n") {
+		if network = strings.TrimSpace(network); network != "" {
+			networks = append(networks, network)
+		}
+	}
+	return networks, nil
+}
+
+// validateClusterOnDestroy checks that on_destroy is either delete or stop.
+func validateClusterOnDestroy(ctx context.Context, config tfsdk.Config) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	var onDestroy types.String
+	diags.Append(config.GetAttribute(ctx, path.Root("on_destroy"), &onDestroy)...)
+	if diags.HasError() || onDestroy.IsNull() || onDestroy.IsUnknown() {
+		return diags
+	}
+
+	if onDestroy.ValueString() != "delete" && onDestroy.ValueString() != "stop" {
+		diags.AddAttributeError(
+			path.Root("on_destroy"),
+			"Invalid on_destroy",
+			fmt.Sprintf("Expected \"delete\" or \"stop\", got: %q.", onDestroy.ValueString()))
+	}
+	return diags
 }
 
 type Kubeconfig struct {
-	Users    []KubeconfigUser    `yaml:"users"`
-	Clusters []KubeconfigCluster `yaml:"clusters"`
+	Users          []KubeconfigUser    `yaml:"users"`
+	Clusters       []KubeconfigCluster `yaml:"clusters"`
+	Contexts       []KubeconfigContext `yaml:"contexts"`
+	CurrentContext string              `yaml:"current-context"`
 }
 
 type KubeconfigUser struct {
+	Name string             `yaml:"name"`
 	User KubeconfigUserData `yaml:"user"`
 }
 
 type KubeconfigUserData struct {
-	ClientCertificateData string `yaml:"client-certificate-data"`
-	ClientKeyData         string `yaml:"client-key-data"`
+	ClientCertificateData string          `yaml:"client-certificate-data"`
+	ClientKeyData         string          `yaml:"client-key-data"`
+	Token                 string          `yaml:"token"`
+	Exec                  *KubeconfigExec `yaml:"exec"`
+}
+
+type KubeconfigExec struct {
+	APIVersion string   `yaml:"apiVersion"`
+	Command    string   `yaml:"command"`
+	Args       []string `yaml:"args"`
 }
 
 type KubeconfigCluster struct {
+	Name    string                `yaml:"name"`
 	Cluster KubeconfigClusterData `yaml:"cluster"`
 }
 
@@ -391,3 +1109,132 @@ type KubeconfigClusterData struct {
 	CertificateAuthorityData string `yaml:"certificate-authority-data"`
 	Server                   string `yaml:"server"`
 }
+
+type KubeconfigContext struct {
+	Name    string                `yaml:"name"`
+	Context KubeconfigContextData `yaml:"context"`
+}
+
+type KubeconfigContextData struct {
+	Cluster string `yaml:"cluster"`
+	User    string `yaml:"user"`
+}
+
+// KubeconfigCredentials are the cluster and user of a kubeconfig context.
+type KubeconfigCredentials struct {
+	ContextName string
+	ClusterName string
+	Cluster     KubeconfigClusterData
+	User        KubeconfigUserData
+}
+
+// Credentials returns the cluster and user of the current context. A
+// kubeconfig without current context must have a single context, or a single
+// cluster and user when it has no contexts.
+func (k Kubeconfig) Credentials() (KubeconfigCredentials, error) {
+	var credentials KubeconfigCredentials
+
+	var current *KubeconfigContext
+	switch {
+	case k.CurrentContext != "":
+		for i := range k.Contexts {
+			if k.Contexts[i].Name == k.CurrentContext {
+				current = &k.Contexts[i]
+			}
+		}
+		if current == nil {
+			return credentials, fmt.Errorf("current context %q does not exist", k.CurrentContext)
+		}
+	case len(k.Contexts) == 1:
+		current = &k.Contexts[0]
+	case len(k.Contexts) > 1:
+		return credentials, fmt.Errorf("kubeconfig has %d contexts and no current context", len(k.Contexts))
+	case len(k.Clusters) == 1 && len(k.Users) == 1:
+		credentials.ClusterName = k.Clusters[0].Name
+		credentials.Cluster = k.Clusters[0].Cluster
+		credentials.User = k.Users[0].User
+		return credentials, nil
+	default:
+		return credentials, fmt.Errorf("kubeconfig has no contexts, %d clusters and %d users", len(k.Clusters), len(k.Users))
+	}
+	credentials.ContextName = current.Name
+
+	clusterFound := false
+	for _, cluster := range k.Clusters {
+		if cluster.Name == current.Context.Cluster {
+			credentials.ClusterName = cluster.Name
+			credentials.Cluster = cluster.Cluster
+			clusterFound = true
+		}
+	}
+	if !clusterFound {
+		return credentials, fmt.Errorf("cluster %q of context %q does not exist", current.Context.Cluster, current.Name)
+	}
+
+	userFound := false
+	for _, user := range k.Users {
+		if user.Name == current.Context.User {
+			credentials.User = user.User
+			userFound = true
+		}
+	}
+	if !userFound {
+		return credentials, fmt.Errorf("user %q of context %q does not exist", current.Context.User, current.Name)
+	}
+	return credentials, nil
+}
+
+func (r *ClusterResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	clusters, diags := listClusters(ctx, r.runner)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	cluster, err := findCluster(clusters, req.ID)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Cannot import non-existent k3d cluster",
+			fmt.Sprintf("A k3d cluster named %q does not exist. List existing clusters with `k3d cluster list`.", req.ID))
+		return
+	}
+
+	config, err := yaml.Marshal(k3dConfigFromCluster(cluster))
+	if err != nil {
+		resp.Diagnostics.AddError("Failed rendering k3d config", fmt.Sprint(err))
+		return
+	}
+
+	// The kubeconfig derived attributes are set by Read after the import.
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), cluster.Name)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("name"), cluster.Name)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("k3d_config"), string(config))...)
+}
+
+func (r *ClusterResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	return map[int64]resource.StateUpgrader{
+		// Version 0 used a checksum of the k3d config as ID, which did not match
+		// the cluster after scaling. The ID is now the cluster name.
+		0: {StateUpgrader: upgradeClusterStateV0},
+	}
+}
+
+// upgradeClusterStateV0 upgrades version 0 states, which hold a subset of the
+// current attributes depending on the provider release that wrote them.
+func upgradeClusterStateV0(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+	raw, err := req.RawState.Unmarshal(resp.State.Schema.Type().TerraformType(ctx))
+	if err != nil {
+		resp.Diagnostics.AddError("Failed upgrading k3d cluster state", fmt.Sprint(err))
+		return
+	}
+	resp.State.Raw = raw
+
+	var data *ClusterResourceModel
+	resp.Diagnostics.Append(resp.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	data.ID = data.Name
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -1,9 +1,21 @@
 package provider
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	fwresource "github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 )
 
@@ -17,7 +29,7 @@ func TestAccClusterResource(t *testing.T) {
 				Config: testAccClusterResourceConfig(),
 				Check: resource.ComposeAggregateTestCheckFunc(
 					resource.TestCheckResourceAttr("k3d_cluster.test", "name", "k3d-provider-test"),
-					resource.TestCheckResourceAttrSet("k3d_cluster.test", "id"),
+					resource.TestCheckResourceAttr("k3d_cluster.test", "id", "k3d-provider-test"),
 					resource.TestCheckResourceAttrSet("k3d_cluster.test", "host"),
 					resource.TestCheckResourceAttrSet("k3d_cluster.test", "client_certificate"),
 					resource.TestCheckResourceAttrSet("k3d_cluster.test", "client_key"),
@@ -37,7 +49,7 @@ func TestAccClusterResource(t *testing.T) {
 				Config: testAccClusterResourceConfig(),
 				Check: resource.ComposeAggregateTestCheckFunc(
 					resource.TestCheckResourceAttr("k3d_cluster.test", "name", "k3d-provider-test"),
-					resource.TestCheckResourceAttrSet("k3d_cluster.test", "id"),
+					resource.TestCheckResourceAttr("k3d_cluster.test", "id", "k3d-provider-test"),
 					resource.TestCheckResourceAttrSet("k3d_cluster.test", "host"),
 					resource.TestCheckResourceAttrSet("k3d_cluster.test", "client_certificate"),
 					resource.TestCheckResourceAttrSet("k3d_cluster.test", "client_key"),
@@ -71,6 +83,46 @@ func TestAccClusterResourceUsage(t *testing.T) {
 	})
 }
 
+func TestAccClusterResourceStructuredConfig(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccClusterResourceStructuredConfig(),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("k3d_cluster.test", "name", "k3d-provider-test"),
+					resource.TestCheckResourceAttr("k3d_cluster.test", "config.agents", "1"),
+					resource.TestCheckResourceAttrSet("k3d_cluster.test", "k3d_config"),
+					resource.TestCheckResourceAttrSet("k3d_cluster.test", "host"),
+				),
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
+
+func testAccClusterResourceStructuredConfig() string {
+	return `
+resource "k3d_cluster" "test" {
+	name = "k3d-provider-test"
+	config = {
+		agents = 1
+		ports = [
+			{
+				port         = "3080:80"
+				node_filters = ["loadbalancer"]
+			},
+		]
+	}
+	wait_for {
+		deployments = ["kube-system/coredns"]
+	}
+}
+`
+}
+
 func testAccClusterResourceConfig() string {
 	return `
 resource "k3d_cluster" "test" {
@@ -139,3 +191,851 @@ resource "kubernetes_config_map" "test" {
 }
 `
 }
+
+const testKubeconfig = `apiVersion: v1
+clusters:
+- cluster:
+    certificate-authority-data: Y2EtZGF0YQ==
+    server: https://0.0.0.0:40123
+  name: k3d-test
+contexts:
+- context:
+    cluster: k3d-test
+    user: admin@k3d-test
+  name: k3d-test
+current-context: k3d-test
+kind: Config
+preferences: {}
+users:
+- name: admin@k3d-test
+  user:
+    client-certificate-data: Y2VydC1kYXRh
+    client-key-data: a2V5LWRhdGE=
+`
+
+const testClusterList = `[{"name":"test","serversCount":1,"serversRunning":1}]`
+
+func TestClusterResourceCreate(t *testing.T) {
+	runner := (&fakeK3dRunner{}).
+		On("", nil, "cluster", "create", "test").
+		On(testKubeconfig, nil, "kubeconfig", "get", "test")
+	r := &ClusterResource{runner: runner}
+
+	req := fwresource.CreateRequest{Plan: newTestPlan(t, testResourceSchema(t, &ClusterResource{}), ClusterResourceModel{
+		Name:      types.StringValue("test"),
+		K3dConfig: types.StringValue("apiVersion: k3d.io/v1alpha4\nkind: Simple\n"),
+	})}
+	resp := &fwresource.CreateResponse{State: newTestState(t, testResourceSchema(t, &ClusterResource{}), nil)}
+	r.Create(context.Background(), req, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+	data := getTestModel[ClusterResourceModel](t, resp.State)
+	if got := data.ID.ValueString(); got != "test" {
+		t.Errorf("expected id test, got %s", got)
+	}
+	if got := data.Host.ValueString(); got != "https://0.0.0.0:40123" {
+		t.Errorf("expected host https://0.0.0.0:40123, got %s", got)
+	}
+	if got := data.ClusterCACertificate.ValueString(); got != "Y2EtZGF0YQ==" {
+		t.Errorf("expected cluster_ca_certificate Y2EtZGF0YQ==, got %s", got)
+	}
+	if got := data.ClientCertificate.ValueString(); got != "Y2VydC1kYXRh" {
+		t.Errorf("expected client_certificate Y2VydC1kYXRh, got %s", got)
+	}
+	if got := data.ClientKey.ValueString(); got != "a2V5LWRhdGE=" {
+		t.Errorf("expected client_key a2V5LWRhdGE=, got %s", got)
+	}
+	if got := data.Kubeconfig.ValueString(); got != testKubeconfig {
+		t.Errorf("expected kubeconfig to match k3d output, got %s", got)
+	}
+}
+
+func TestClusterResourceCreateFailed(t *testing.T) {
+	runner := (&fakeK3dRunner{}).
+		On("FATA[0000] some failure", errors.New("exit status 1"), "cluster", "create", "test")
+	r := &ClusterResource{runner: runner}
+
+	req := fwresource.CreateRequest{Plan: newTestPlan(t, testResourceSchema(t, &ClusterResource{}), ClusterResourceModel{
+		Name:      types.StringValue("test"),
+		K3dConfig: types.StringValue("apiVersion: k3d.io/v1alpha4\nkind: Simple\n"),
+	})}
+	resp := &fwresource.CreateResponse{State: newTestState(t, testResourceSchema(t, &ClusterResource{}), nil)}
+	r.Create(context.Background(), req, resp)
+
+	if !resp.Diagnostics.HasError() {
+		t.Fatal("expected error")
+	}
+	if runner.Called("kubeconfig", "get") {
+		t.Error("expected kubeconfig not to be read after failed create")
+	}
+}
+
+func TestClusterResourceCreateInvalidConfig(t *testing.T) {
+	runner := (&fakeK3dRunner{}).
+		On("FATA[0000] Schema Validation failed for config file", errors.New("exit status 1"), "cluster", "create", "test")
+	r := &ClusterResource{runner: runner}
+
+	cases := map[string]ClusterResourceModel{
+		"k3d_config": {
+			Name:      types.StringValue("test"),
+			K3dConfig: types.StringValue("apiVersion: k3d.io/v1alpha4\nkind: Simple\n"),
+		},
+		"config": {
+			Name:      types.StringValue("test"),
+			K3dConfig: types.StringValue("apiVersion: k3d.io/v1alpha4\nkind: Simple\nservers: 1\n"),
+			Config:    &ClusterConfigModel{Servers: types.Int64Value(1)},
+		},
+	}
+	for attribute, data := range cases {
+		req := fwresource.CreateRequest{Plan: newTestPlan(t, testResourceSchema(t, &ClusterResource{}), data)}
+		resp := &fwresource.CreateResponse{State: newTestState(t, testResourceSchema(t, &ClusterResource{}), nil)}
+		r.Create(context.Background(), req, resp)
+
+		if len(resp.Diagnostics) != 1 {
+			t.Fatalf("expected 1 diagnostic, got %v", resp.Diagnostics)
+		}
+		withPath, ok := resp.Diagnostics[0].(diag.DiagnosticWithPath)
+		if !ok || !withPath.Path().Equal(path.Root(attribute)) {
+			t.Errorf("expected diagnostic for %s, got %v", attribute, resp.Diagnostics[0])
+		}
+	}
+}
+
+func TestClusterResourceCreateInvalidKubeconfig(t *testing.T) {
+	runner := (&fakeK3dRunner{}).
+		On("", nil, "cluster", "create", "test").
+		On("clusters: [", nil, "kubeconfig", "get", "test")
+	r := &ClusterResource{runner: runner}
+
+	req := fwresource.CreateRequest{Plan: newTestPlan(t, testResourceSchema(t, &ClusterResource{}), ClusterResourceModel{
+		Name:      types.StringValue("test"),
+		K3dConfig: types.StringValue("apiVersion: k3d.io/v1alpha4\nkind: Simple\n"),
+	})}
+	resp := &fwresource.CreateResponse{State: newTestState(t, testResourceSchema(t, &ClusterResource{}), nil)}
+	r.Create(context.Background(), req, resp)
+
+	if !resp.Diagnostics.HasError() {
+		t.Fatal("expected error")
+	}
+}
+
+func TestClusterResourceRead(t *testing.T) {
+	runner := (&fakeK3dRunner{}).
+		On(testClusterList, nil, "cluster", "list").
+		On(testKubeconfig, nil, "kubeconfig", "get", "test")
+	r := &ClusterResource{runner: runner}
+
+	state := newTestState(t, testResourceSchema(t, &ClusterResource{}), &ClusterResourceModel{
+		ID:        types.StringValue("id"),
+		Name:      types.StringValue("test"),
+		K3dConfig: types.StringValue("apiVersion: k3d.io/v1alpha4\nkind: Simple\n"),
+	})
+	resp := &fwresource.ReadResponse{State: state}
+	r.Read(context.Background(), fwresource.ReadRequest{State: state}, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+	data := getTestModel[ClusterResourceModel](t, resp.State)
+	if got := data.Host.ValueString(); got != "https://0.0.0.0:40123" {
+		t.Errorf("expected host https://0.0.0.0:40123, got %s", got)
+	}
+	if got := data.K3dConfig.ValueString(); got != "apiVersion: k3d.io/v1alpha4\nkind: Simple\n" {
+		t.Errorf("expected k3d_config to be unchanged, got %s", got)
+	}
+}
+
+func TestClusterResourceReadDrift(t *testing.T) {
+	runner := (&fakeK3dRunner{}).
+		On(testClusterListDetailed, nil, "cluster", "list").
+		On(testKubeconfig, nil, "kubeconfig", "get", "test")
+	r := &ClusterResource{runner: runner}
+
+	// One of the agents was deleted outside of Terraform.
+	state := newTestState(t, testResourceSchema(t, &ClusterResource{}), &ClusterResourceModel{
+		ID:        types.StringValue("id"),
+		Name:      types.StringValue("test"),
+		K3dConfig: types.StringValue("apiVersion: k3d.io/v1alpha4\nkind: Simple\nagents: 2\n"),
+	})
+	resp := &fwresource.ReadResponse{State: state}
+	r.Read(context.Background(), fwresource.ReadRequest{State: state}, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+	data := getTestModel[ClusterResourceModel](t, resp.State)
+	config, err := parseK3dConfig(data.K3dConfig.ValueString())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if counts := k3dConfigNodeCounts(config); counts.Agents != 1 {
+		t.Errorf("expected observed 1 agent in k3d_config, got %d", counts.Agents)
+	}
+}
+
+func TestClusterResourceReadMissing(t *testing.T) {
+	runner := (&fakeK3dRunner{}).
+		On("[]", nil, "cluster", "list")
+	r := &ClusterResource{runner: runner}
+
+	state := newTestState(t, testResourceSchema(t, &ClusterResource{}), &ClusterResourceModel{
+		ID:        types.StringValue("id"),
+		Name:      types.StringValue("test"),
+		K3dConfig: types.StringValue("apiVersion: k3d.io/v1alpha4\nkind: Simple\n"),
+	})
+	resp := &fwresource.ReadResponse{State: state}
+	r.Read(context.Background(), fwresource.ReadRequest{State: state}, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+	if !resp.State.Raw.IsNull() {
+		t.Error("expected resource to be removed from state")
+	}
+}
+
+func TestClusterResourceReadListFailed(t *testing.T) {
+	runner := (&fakeK3dRunner{}).
+		On("", errors.New("exit status 1"), "cluster", "list")
+	r := &ClusterResource{runner: runner}
+
+	state := newTestState(t, testResourceSchema(t, &ClusterResource{}), &ClusterResourceModel{
+		ID:        types.StringValue("id"),
+		Name:      types.StringValue("test"),
+		K3dConfig: types.StringValue("apiVersion: k3d.io/v1alpha4\nkind: Simple\n"),
+	})
+	resp := &fwresource.ReadResponse{State: state}
+	r.Read(context.Background(), fwresource.ReadRequest{State: state}, resp)
+
+	if !resp.Diagnostics.HasError() {
+		t.Fatal("expected error")
+	}
+}
+
+func TestClusterResourceReadStopped(t *testing.T) {
+	runner := (&fakeK3dRunner{}).
+		On(`[{"name":"test","serversCount":1,"serversRunning":0}]`, nil, "cluster", "list")
+	r := &ClusterResource{runner: runner}
+
+	state := newTestState(t, testResourceSchema(t, &ClusterResource{}), &ClusterResourceModel{
+		ID:        types.StringValue("id"),
+		Name:      types.StringValue("test"),
+		K3dConfig: types.StringValue("apiVersion: k3d.io/v1alpha4\nkind: Simple\n"),
+		Host:      types.StringValue("https://0.0.0.0:40123"),
+		Running:   types.BoolValue(true),
+	})
+	resp := &fwresource.ReadResponse{State: state}
+	r.Read(context.Background(), fwresource.ReadRequest{State: state}, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+	data := getTestModel[ClusterResourceModel](t, resp.State)
+	if data.Running.ValueBool() {
+		t.Error("expected running to be false")
+	}
+	if got := data.Host.ValueString(); got != "https://0.0.0.0:40123" {
+		t.Errorf("expected host to be kept, got %s", got)
+	}
+	if runner.Called("kubeconfig", "get") {
+		t.Error("expected kubeconfig not to be read for a stopped cluster")
+	}
+}
+
+func TestClusterResourceUpdateStartsCluster(t *testing.T) {
+	runner := (&fakeK3dRunner{}).
+		On("", nil, "cluster", "start", "test").
+		On(testKubeconfig, nil, "kubeconfig", "get", "test")
+	r := &ClusterResource{runner: runner}
+
+	state := newTestState(t, testResourceSchema(t, &ClusterResource{}), &ClusterResourceModel{
+		ID:            types.StringValue("id"),
+		Name:          types.StringValue("test"),
+		K3dConfig:     types.StringValue("apiVersion: k3d.io/v1alpha4\nkind: Simple\n"),
+		EnsureRunning: types.BoolValue(true),
+		Running:       types.BoolValue(false),
+	})
+	plan := newTestPlan(t, testResourceSchema(t, &ClusterResource{}), ClusterResourceModel{
+		ID:            types.StringUnknown(),
+		Name:          types.StringValue("test"),
+		K3dConfig:     types.StringValue("apiVersion: k3d.io/v1alpha4\nkind: Simple\n"),
+		EnsureRunning: types.BoolValue(true),
+		Running:       types.BoolValue(true),
+	})
+	resp := &fwresource.UpdateResponse{State: state}
+	r.Update(context.Background(), fwresource.UpdateRequest{Plan: plan, State: state}, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+	if !runner.Called("cluster", "start", "test") {
+		t.Error("expected cluster to be started")
+	}
+	data := getTestModel[ClusterResourceModel](t, resp.State)
+	if !data.Running.ValueBool() {
+		t.Error("expected running to be true")
+	}
+	if got := data.ID.ValueString(); got != "id" {
+		t.Errorf("expected id to be kept, got %s", got)
+	}
+}
+
+func TestClusterResourceUpdateScalesAgents(t *testing.T) {
+	runner := (&fakeK3dRunner{}).
+		On(`[{"name":"test","serversCount":1,"serversRunning":1,"nodes":[
+			{"name":"k3d-test-server-0","role":"server","image":"rancher/k3s:v1.24.4-k3s1","created":"2022-12-01T10:00:00Z"},
+			{"name":"k3d-test-agent-0","role":"agent","image":"rancher/k3s:v1.24.4-k3s1","created":"2022-12-01T10:00:01Z"},
+			{"name":"k3d-test-agent-1","role":"agent","image":"rancher/k3s:v1.24.4-k3s1","created":"2022-12-01T10:00:02Z"},
+			{"name":"k3d-test-serverlb","role":"loadbalancer","image":"ghcr.io/k3d-io/k3d-proxy:5.4.6","created":"2022-12-01T10:00:03Z"}
+		]}]`, nil, "cluster", "list").
+		On("", nil, "node", "delete", "k3d-test-agent-1").
+		On(testKubeconfig, nil, "kubeconfig", "get", "test")
+	r := &ClusterResource{runner: runner}
+
+	state := newTestState(t, testResourceSchema(t, &ClusterResource{}), &ClusterResourceModel{
+		ID:        types.StringValue("id"),
+		Name:      types.StringValue("test"),
+		K3dConfig: types.StringValue("apiVersion: k3d.io/v1alpha4\nkind: Simple\nagents: 2\n"),
+		Running:   types.BoolValue(true),
+	})
+	plan := newTestPlan(t, testResourceSchema(t, &ClusterResource{}), ClusterResourceModel{
+		ID:        types.StringUnknown(),
+		Name:      types.StringValue("test"),
+		K3dConfig: types.StringValue("apiVersion: k3d.io/v1alpha4\nkind: Simple\nagents: 1\n"),
+		Running:   types.BoolUnknown(),
+	})
+	resp := &fwresource.UpdateResponse{State: state}
+	r.Update(context.Background(), fwresource.UpdateRequest{Plan: plan, State: state}, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+	if !runner.Called("node", "delete", "k3d-test-agent-1") {
+		t.Error("expected newest agent to be deleted")
+	}
+	if runner.Called("node", "delete", "k3d-test-agent-0") || runner.Called("node", "create") {
+		t.Errorf("expected only one agent to be deleted, got calls %v", runner.calls)
+	}
+	data := getTestModel[ClusterResourceModel](t, resp.State)
+	if got := data.K3dConfig.ValueString(); got != "apiVersion: k3d.io/v1alpha4\nkind: Simple\nagents: 1\n" {
+		t.Errorf("expected new k3d_config in state, got %s", got)
+	}
+}
+
+func TestClusterResourceUpdateScalesAgentsFreeNames(t *testing.T) {
+	// An earlier scale up created k3d-test-agent-1-0, then k3d-test-agent-0
+	// was deleted outside of Terraform and read back as a single agent.
+	runner := (&fakeK3dRunner{}).
+		On(`[{"name":"test","serversCount":1,"serversRunning":1,"nodes":[
+			{"name":"k3d-test-server-0","role":"server","image":"rancher/k3s:v1.24.4-k3s1","created":"2022-12-01T10:00:00Z"},
+			{"name":"k3d-test-agent-1-0","role":"agent","image":"rancher/k3s:v1.24.4-k3s1","created":"2022-12-01T10:00:02Z"},
+			{"name":"k3d-test-serverlb","role":"loadbalancer","image":"ghcr.io/k3d-io/k3d-proxy:5.4.6","created":"2022-12-01T10:00:03Z"}
+		]}]`, nil, "cluster", "list").
+		On("", nil, "node", "create").
+		On(testKubeconfig, nil, "kubeconfig", "get", "test")
+	r := &ClusterResource{runner: runner}
+
+	state := newTestState(t, testResourceSchema(t, &ClusterResource{}), &ClusterResourceModel{
+		ID:        types.StringValue("id"),
+		Name:      types.StringValue("test"),
+		K3dConfig: types.StringValue("apiVersion: k3d.io/v1alpha4\nkind: Simple\nagents: 1\n"),
+		Running:   types.BoolValue(true),
+	})
+	plan := newTestPlan(t, testResourceSchema(t, &ClusterResource{}), ClusterResourceModel{
+		ID:        types.StringUnknown(),
+		Name:      types.StringValue("test"),
+		K3dConfig: types.StringValue("apiVersion: k3d.io/v1alpha4\nkind: Simple\nagents: 2\n"),
+		Running:   types.BoolUnknown(),
+	})
+	resp := &fwresource.UpdateResponse{State: state}
+	r.Update(context.Background(), fwresource.UpdateRequest{Plan: plan, State: state}, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+	if !runner.Called("node", "create", "test-agent-0", "--cluster", "test", "--role", "agent") {
+		t.Errorf("expected agent test-agent-0 to be created, got calls %v", runner.calls)
+	}
+	if runner.Called("node", "create", "test-agent-1") {
+		t.Error("expected existing agent name not to be reused")
+	}
+}
+
+func TestClusterResourceUpdateScalesServers(t *testing.T) {
+	runner := (&fakeK3dRunner{}).
+		On(`[{"name":"test","serversCount":3,"serversRunning":3,"nodes":[
+			{"name":"k3d-test-server-0","role":"server","image":"rancher/k3s:v1.24.4-k3s1","created":"2022-10-01T10:00:00Z"},
+			{"name":"k3d-test-server-1","role":"server","image":"rancher/k3s:v1.24.4-k3s1","created":"2022-10-01T10:00:01Z"},
+			{"name":"k3d-test-server-2","role":"server","image":"rancher/k3s:v1.24.4-k3s1","created":"2022-10-01T10:00:02Z"}
+		]}]`, nil, "cluster", "list").
+		On("", nil, "node", "create").
+		On(testKubeconfig, nil, "kubeconfig", "get", "test")
+	r := &ClusterResource{runner: runner}
+
+	// Clusters with multiple servers run embedded etcd, which new servers join.
+	state := newTestState(t, testResourceSchema(t, &ClusterResource{}), &ClusterResourceModel{
+		ID:        types.StringValue("id"),
+		Name:      types.StringValue("test"),
+		K3dConfig: types.StringValue("apiVersion: k3d.io/v1alpha4\nkind: Simple\nservers: 3\n"),
+		Running:   types.BoolValue(true),
+	})
+	plan := newTestPlan(t, testResourceSchema(t, &ClusterResource{}), ClusterResourceModel{
+		ID:        types.StringUnknown(),
+		Name:      types.StringValue("test"),
+		K3dConfig: types.StringValue("apiVersion: k3d.io/v1alpha4\nkind: Simple\nservers: 5\n"),
+		Running:   types.BoolUnknown(),
+	})
+	resp := &fwresource.UpdateResponse{State: state}
+	r.Update(context.Background(), fwresource.UpdateRequest{Plan: plan, State: state}, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+	for _, node := range []string{"test-server-3", "test-server-4"} {
+		if !runner.Called("node", "create", node, "--cluster", "test", "--role", "server", "--wait", "--image", "rancher/k3s:v1.24.4-k3s1") {
+			t.Errorf("expected server node %s to be created, got calls %v", node, runner.calls)
+		}
+	}
+}
+
+func TestClusterResourceUpdateImmutableConfig(t *testing.T) {
+	runner := &fakeK3dRunner{}
+	r := &ClusterResource{runner: runner}
+
+	state := newTestState(t, testResourceSchema(t, &ClusterResource{}), &ClusterResourceModel{
+		ID:        types.StringValue("id"),
+		Name:      types.StringValue("test"),
+		K3dConfig: types.StringValue("apiVersion: k3d.io/v1alpha4\nkind: Simple\n"),
+		Running:   types.BoolValue(true),
+	})
+	plan := newTestPlan(t, testResourceSchema(t, &ClusterResource{}), ClusterResourceModel{
+		ID:        types.StringUnknown(),
+		Name:      types.StringValue("test"),
+		K3dConfig: types.StringValue("apiVersion: k3d.io/v1alpha4\nkind: Simple\nimage: rancher/k3s:v1.25.4-k3s1\n"),
+		Running:   types.BoolUnknown(),
+	})
+	resp := &fwresource.UpdateResponse{State: state}
+	r.Update(context.Background(), fwresource.UpdateRequest{Plan: plan, State: state}, resp)
+
+	if !resp.Diagnostics.HasError() {
+		t.Fatal("expected error")
+	}
+	if len(runner.calls) != 0 {
+		t.Errorf("expected no k3d commands, got %v", runner.calls)
+	}
+}
+
+func TestClusterResourceDelete(t *testing.T) {
+	runner := (&fakeK3dRunner{}).
+		On(testClusterList, nil, "cluster", "list").
+		On("", nil, "cluster", "delete", "test").
+		On("", nil, "node", "list")
+	r := &ClusterResource{runner: runner}
+
+	state := newTestState(t, testResourceSchema(t, &ClusterResource{}), &ClusterResourceModel{
+		ID:        types.StringValue("id"),
+		Name:      types.StringValue("test"),
+		K3dConfig: types.StringValue("apiVersion: k3d.io/v1alpha4\nkind: Simple\n"),
+	})
+	resp := &fwresource.DeleteResponse{State: state}
+	r.Delete(context.Background(), fwresource.DeleteRequest{State: state}, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+	if !runner.Called("cluster", "delete", "test") {
+		t.Error("expected cluster to be deleted")
+	}
+	if !runner.Called("node", "list") {
+		t.Error("expected nodes to be checked after deleting the cluster")
+	}
+}
+
+func TestClusterResourceDeleteFailed(t *testing.T) {
+	runner := (&fakeK3dRunner{}).
+		On(testClusterList, nil, "cluster", "list").
+		On("FATA[0000] failed to delete cluster", errors.New("exit status 1"), "cluster", "delete", "test")
+	r := &ClusterResource{runner: runner}
+
+	state := newTestState(t, testResourceSchema(t, &ClusterResource{}), &ClusterResourceModel{
+		ID:        types.StringValue("id"),
+		Name:      types.StringValue("test"),
+		K3dConfig: types.StringValue("apiVersion: k3d.io/v1alpha4\nkind: Simple\n"),
+	})
+	resp := &fwresource.DeleteResponse{State: state}
+	r.Delete(context.Background(), fwresource.DeleteRequest{State: state}, resp)
+
+	if !resp.Diagnostics.HasError() {
+		t.Fatal("expected error")
+	}
+	if got := resp.Diagnostics[0].Detail(); !strings.Contains(got, "failed to delete cluster") {
+		t.Errorf("expected k3d output in error, got %q", got)
+	}
+}
+
+func TestClusterResourceDeleteMissing(t *testing.T) {
+	runner := (&fakeK3dRunner{}).
+		On("[]", nil, "cluster", "list")
+	r := &ClusterResource{runner: runner}
+
+	state := newTestState(t, testResourceSchema(t, &ClusterResource{}), &ClusterResourceModel{
+		ID:        types.StringValue("test"),
+		Name:      types.StringValue("test"),
+		K3dConfig: types.StringValue("apiVersion: k3d.io/v1alpha4\nkind: Simple\n"),
+	})
+	resp := &fwresource.DeleteResponse{State: state}
+	r.Delete(context.Background(), fwresource.DeleteRequest{State: state}, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+	if runner.Called("cluster", "delete") {
+		t.Error("expected already deleted cluster not to be deleted again")
+	}
+}
+
+func TestClusterResourceDeleteLeftovers(t *testing.T) {
+	runner := (&fakeK3dRunner{}).
+		On(`[{"name":"test","network":{"name":"k3d-test"},"serversCount":1,"serversRunning":1}]`, nil, "cluster", "list").
+		On("", nil, "cluster", "delete", "test").
+		On(`[
+			{"name":"k3d-test-serverlb","role":"loadbalancer","runtimeLabels":{"k3d.cluster":"test"}},
+			{"name":"k3d-other-server-0","role":"server","runtimeLabels":{"k3d.cluster":"other"}},
+			{"name":"k3d-dev","role":"registry","runtimeLabels":{"k3d.cluster":"test"}}
+		]`, nil, "node", "list")
+	docker := (&fakeK3dRunner{}).
+		On("bridge\nk3d-test\nk3d-other\n", nil, "network", "ls")
+	r := &ClusterResource{runner: runner, docker: docker}
+
+	state := newTestState(t, testResourceSchema(t, &ClusterResource{}), &ClusterResourceModel{
+		ID:        types.StringValue("test"),
+		Name:      types.StringValue("test"),
+		K3dConfig: types.StringValue("apiVersion: k3d.io/v1alpha4\nkind: Simple\n"),
+	})
+	resp := &fwresource.DeleteResponse{State: state}
+	r.Delete(context.Background(), fwresource.DeleteRequest{State: state}, resp)
+
+	if !resp.Diagnostics.HasError() {
+		t.Fatal("expected error")
+	}
+	want := "left behind: loadbalancer node k3d-test-serverlb, network k3d-test."
+	if got := resp.Diagnostics[0].Detail(); !strings.Contains(got, want) {
+		t.Errorf("expected leftovers %q in error, got %q", want, got)
+	}
+}
+
+func TestClusterResourceDeleteWithoutDocker(t *testing.T) {
+	runner := (&fakeK3dRunner{}).
+		On(`[{"name":"test","network":{"name":"k3d-test"},"serversCount":1,"serversRunning":1}]`, nil, "cluster", "list").
+		On("", nil, "cluster", "delete", "test").
+		On("", nil, "node", "list")
+	docker := (&fakeK3dRunner{}).
+		On("", fmt.Errorf("%w: executable file not found in $PATH", errDockerUnavailable), "network", "ls")
+	r := &ClusterResource{runner: runner, docker: docker}
+
+	state := newTestState(t, testResourceSchema(t, &ClusterResource{}), &ClusterResourceModel{
+		ID:        types.StringValue("test"),
+		Name:      types.StringValue("test"),
+		K3dConfig: types.StringValue("apiVersion: k3d.io/v1alpha4\nkind: Simple\n"),
+	})
+	resp := &fwresource.DeleteResponse{State: state}
+	r.Delete(context.Background(), fwresource.DeleteRequest{State: state}, resp)
+
+	// The network check is skipped, the deleted cluster is not an error.
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+	if resp.Diagnostics.WarningsCount() != 1 || resp.Diagnostics[0].Summary() != "Skipped checking for a leftover Docker network" {
+		t.Errorf("expected warning about the skipped network check, got %v", resp.Diagnostics)
+	}
+}
+
+func TestClusterResourceDeleteProtected(t *testing.T) {
+	runner := &fakeK3dRunner{}
+	r := &ClusterResource{runner: runner}
+
+	state := newTestState(t, testResourceSchema(t, &ClusterResource{}), &ClusterResourceModel{
+		ID:                 types.StringValue("test"),
+		Name:               types.StringValue("test"),
+		K3dConfig:          types.StringValue("apiVersion: k3d.io/v1alpha4\nkind: Simple\n"),
+		DeletionProtection: types.BoolValue(true),
+	})
+	resp := &fwresource.DeleteResponse{State: state}
+	r.Delete(context.Background(), fwresource.DeleteRequest{State: state}, resp)
+
+	if !resp.Diagnostics.HasError() {
+		t.Fatal("expected error")
+	}
+	if got := resp.Diagnostics[0].Summary(); got != "Cluster is protected from deletion" {
+		t.Errorf("expected deletion protection error, got %q", got)
+	}
+	if len(runner.calls) != 0 {
+		t.Errorf("expected k3d not to be called, got %v", runner.calls)
+	}
+}
+
+func TestClusterResourceDeleteOnDestroyStop(t *testing.T) {
+	runner := (&fakeK3dRunner{}).
+		On(testClusterList, nil, "cluster", "list").
+		On("", nil, "cluster", "stop", "test")
+	r := &ClusterResource{runner: runner}
+
+	defaultPath := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(defaultPath, []byte(testKubeconfigMerged), 0600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Setenv("KUBECONFIG", defaultPath)
+	state := newTestState(t, testResourceSchema(t, &ClusterResource{}), &ClusterResourceModel{
+		ID:                     types.StringValue("test"),
+		Name:                   types.StringValue("test"),
+		K3dConfig:              types.StringValue("apiVersion: k3d.io/v1alpha4\nkind: Simple\n"),
+		MergeDefaultKubeconfig: types.BoolValue(true),
+		OnDestroy:              types.StringValue("stop"),
+	})
+	resp := &fwresource.DeleteResponse{State: state}
+	r.Delete(context.Background(), fwresource.DeleteRequest{State: state}, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+	if !runner.Called("cluster", "stop", "test") {
+		t.Errorf("expected cluster to be stopped, got %v", runner.calls)
+	}
+	if runner.Called("cluster", "delete") {
+		t.Error("expected cluster not to be deleted")
+	}
+	// k3d only removes deleted clusters from the default kubeconfig.
+	if content, err := os.ReadFile(defaultPath); err != nil || strings.Contains(string(content), "k3d-test") {
+		t.Errorf("expected stopped cluster to be removed from the default kubeconfig, got %v:\n%s", err, content)
+	}
+}
+
+func TestClusterResourceModifyPlanOnDestroyStop(t *testing.T) {
+	prior := ClusterResourceModel{
+		ID:        types.StringValue("test"),
+		Name:      types.StringValue("test"),
+		K3dConfig: types.StringValue("apiVersion: k3d.io/v1alpha4\nkind: Simple\nagents: 1\n"),
+		OnDestroy: types.StringValue("stop"),
+	}
+	cases := []struct {
+		name      string
+		onDestroy string
+		plan      func(data *ClusterResourceModel)
+		wantError bool
+	}{
+		{"agents changed", "stop", func(data *ClusterResourceModel) {
+			data.K3dConfig = types.StringValue("apiVersion: k3d.io/v1alpha4\nkind: Simple\nagents: 2\n")
+		}, false},
+		{"image changed", "stop", func(data *ClusterResourceModel) {
+			data.K3dConfig = types.StringValue("apiVersion: k3d.io/v1alpha4\nkind: Simple\nagents: 1\nimage: rancher/k3s:v1.25.4-k3s1\n")
+		}, true},
+		{"name changed", "stop", func(data *ClusterResourceModel) { data.Name = types.StringValue("renamed") }, true},
+		{"api_host_override added", "stop", func(data *ClusterResourceModel) {
+			data.APIHostOverride = types.StringValue("host.docker.internal")
+		}, true},
+		{"name changed and deleted on destroy", "delete", func(data *ClusterResourceModel) { data.Name = types.StringValue("renamed") }, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			state := prior
+			state.OnDestroy = types.StringValue(c.onDestroy)
+			planned := state
+			c.plan(&planned)
+
+			plan := newTestPlan(t, testResourceSchema(t, &ClusterResource{}), planned)
+			req := fwresource.ModifyPlanRequest{State: newTestState(t, testResourceSchema(t, &ClusterResource{}), &state), Plan: plan}
+			resp := &fwresource.ModifyPlanResponse{Plan: plan}
+			(&ClusterResource{}).ModifyPlan(context.Background(), req, resp)
+
+			if got := resp.Diagnostics.HasError(); got != c.wantError {
+				t.Errorf("expected error %t, got %v", c.wantError, resp.Diagnostics)
+			}
+		})
+	}
+}
+
+func TestClusterResourceModifyPlanDeletionProtection(t *testing.T) {
+	prior := ClusterResourceModel{
+		ID:                 types.StringValue("test"),
+		Name:               types.StringValue("test"),
+		K3dConfig:          types.StringValue("apiVersion: k3d.io/v1alpha4\nkind: Simple\nagents: 1\n"),
+		OnDestroy:          types.StringValue("delete"),
+		DeletionProtection: types.BoolValue(true),
+	}
+	cases := []struct {
+		name               string
+		deletionProtection bool
+		plan               func(data *ClusterResourceModel)
+		wantError          bool
+	}{
+		{"agents changed", true, func(data *ClusterResourceModel) {
+			data.K3dConfig = types.StringValue("apiVersion: k3d.io/v1alpha4\nkind: Simple\nagents: 2\n")
+		}, false},
+		{"image changed", true, func(data *ClusterResourceModel) {
+			data.K3dConfig = types.StringValue("apiVersion: k3d.io/v1alpha4\nkind: Simple\nagents: 1\nimage: rancher/k3s:v1.25.4-k3s1\n")
+		}, true},
+		{"name changed", true, func(data *ClusterResourceModel) { data.Name = types.StringValue("renamed") }, true},
+		{"name changed without protection", false, func(data *ClusterResourceModel) { data.Name = types.StringValue("renamed") }, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			state := prior
+			state.DeletionProtection = types.BoolValue(c.deletionProtection)
+			planned := state
+			c.plan(&planned)
+
+			plan := newTestPlan(t, testResourceSchema(t, &ClusterResource{}), planned)
+			req := fwresource.ModifyPlanRequest{State: newTestState(t, testResourceSchema(t, &ClusterResource{}), &state), Plan: plan}
+			resp := &fwresource.ModifyPlanResponse{Plan: plan}
+			(&ClusterResource{}).ModifyPlan(context.Background(), req, resp)
+
+			if got := resp.Diagnostics.HasError(); got != c.wantError {
+				t.Errorf("expected error %t, got %v", c.wantError, resp.Diagnostics)
+			}
+		})
+	}
+}
+
+func TestClusterResourceImportState(t *testing.T) {
+	runner := (&fakeK3dRunner{}).
+		On(`[{"name":"test","serversCount":1,"serversRunning":1,"nodes":[
+			{"name":"k3d-test-server-0","role":"server","image":"rancher/k3s:v1.24.4-k3s1"}
+		]}]`, nil, "cluster", "list")
+	r := &ClusterResource{runner: runner}
+
+	resp := &fwresource.ImportStateResponse{State: newTestState(t, testResourceSchema(t, &ClusterResource{}), nil)}
+	r.ImportState(context.Background(), fwresource.ImportStateRequest{ID: "test"}, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+	data := getTestModel[ClusterResourceModel](t, resp.State)
+	if got := data.Name.ValueString(); got != "test" {
+		t.Errorf("expected name test, got %s", got)
+	}
+	want := "apiVersion: k3d.io/v1alpha4\nkind: Simple\nservers: 1\nagents: 0\nimage: rancher/k3s:v1.24.4-k3s1\n"
+	if got := data.K3dConfig.ValueString(); got != want {
+		t.Errorf("expected k3d_config %q, got %q", want, got)
+	}
+	if got := data.ID.ValueString(); got != "test" {
+		t.Errorf("expected id test, got %s", got)
+	}
+}
+
+func TestClusterResourceImportStatePlanMinimalConfig(t *testing.T) {
+	runner := (&fakeK3dRunner{}).
+		On(`[{"name":"test","serversCount":1,"serversRunning":1,"nodes":[
+			{"name":"k3d-test-server-0","role":"server","image":"rancher/k3s:v1.24.4-k3s1"},
+			{"name":"k3d-test-serverlb","role":"loadbalancer","image":"ghcr.io/k3d-io/k3d-proxy:5.4.6",
+				"portMappings": {"6443/tcp": [{"HostIp": "0.0.0.0", "HostPort": "40123"}]}}
+		]}]`, nil, "cluster", "list")
+	r := &ClusterResource{runner: runner}
+
+	resp := &fwresource.ImportStateResponse{State: newTestState(t, testResourceSchema(t, &ClusterResource{}), nil)}
+	r.ImportState(context.Background(), fwresource.ImportStateRequest{ID: "test"}, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+	imported := getTestModel[ClusterResourceModel](t, resp.State).K3dConfig
+	for _, config := range []string{
+		"apiVersion: k3d.io/v1alpha4\nkind: Simple\n",
+		"apiVersion: k3d.io/v1alpha4\nkind: Simple\nkubeAPI:\n  hostPort: \"40123\"\n",
+	} {
+		if k3dConfigChangeRequiresReplace(imported, types.StringValue(config)) {
+			t.Errorf("expected imported k3d_config %q to accept %q without replacement", imported.ValueString(), config)
+		}
+	}
+	changed := "apiVersion: k3d.io/v1alpha4\nkind: Simple\nimage: rancher/k3s:v1.25.2-k3s1\n"
+	if !k3dConfigChangeRequiresReplace(imported, types.StringValue(changed)) {
+		t.Error("expected a changed image to require replacement")
+	}
+}
+
+func TestClusterResourceImportStateMissing(t *testing.T) {
+	runner := (&fakeK3dRunner{}).
+		On("[]", nil, "cluster", "list")
+	r := &ClusterResource{runner: runner}
+
+	resp := &fwresource.ImportStateResponse{State: newTestState(t, testResourceSchema(t, &ClusterResource{}), nil)}
+	r.ImportState(context.Background(), fwresource.ImportStateRequest{ID: "test"}, resp)
+
+	if !resp.Diagnostics.HasError() {
+		t.Fatal("expected error")
+	}
+}
+
+func TestClusterResourceUpgradeStateV0(t *testing.T) {
+	// State written by a provider release before ensure_running and config.
+	rawState := &tfprotov6.RawState{JSON: []byte(`{
+		"id": "0cc175b9c0f1b6a831c399e269772661",
+		"name": "test",
+		"k3d_config": "apiVersion: k3d.io/v1alpha4\nkind: Simple\n",
+		"kubeconfig": "kubeconfig-data",
+		"host": "https://0.0.0.0:40123",
+		"client_certificate": "Y2VydC1kYXRh",
+		"client_key": "a2V5LWRhdGE=",
+		"cluster_ca_certificate": "Y2EtZGF0YQ=="
+	}`)}
+	upgrader, ok := (&ClusterResource{}).UpgradeState(context.Background())[0]
+	if !ok {
+		t.Fatal("expected state upgrader for version 0")
+	}
+
+	resp := &fwresource.UpgradeStateResponse{State: tfsdk.State{Schema: testResourceSchema(t, &ClusterResource{})}}
+	upgrader.StateUpgrader(context.Background(), fwresource.UpgradeStateRequest{RawState: rawState}, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+	data := getTestModel[ClusterResourceModel](t, resp.State)
+	if got := data.ID.ValueString(); got != "test" {
+		t.Errorf("expected id test, got %s", got)
+	}
+	if got := data.Host.ValueString(); got != "https://0.0.0.0:40123" {
+		t.Errorf("expected host to be kept, got %s", got)
+	}
+	if !data.EnsureRunning.IsNull() {
+		t.Errorf("expected ensure_running to be null, got %s", data.EnsureRunning)
+	}
+}
+
+func TestClusterResourceValidateConfig(t *testing.T) {
+	cases := []struct {
+		name    string
+		data    ClusterResourceModel
+		wantErr bool
+	}{
+		{"k3d_config", ClusterResourceModel{K3dConfig: types.StringValue("apiVersion: k3d.io/v1alpha4\nkind: Simple\n")}, false},
+		{"config", ClusterResourceModel{Config: &ClusterConfigModel{}}, false},
+		{"both", ClusterResourceModel{K3dConfig: types.StringValue("apiVersion: k3d.io/v1alpha4\nkind: Simple\n"), Config: &ClusterConfigModel{}}, true},
+		{"neither", ClusterResourceModel{}, true},
+		{"invalid k3d_config", ClusterResourceModel{K3dConfig: types.StringValue("apiVersion: k3d.io/v1alpha4\nkind: Simple\nagents: two\n")}, true},
+		{"unknown k3d_config", ClusterResourceModel{K3dConfig: types.StringUnknown()}, false},
+		{"timeouts", ClusterResourceModel{K3dConfig: types.StringValue("apiVersion: k3d.io/v1alpha4\nkind: Simple\n"), Timeouts: &ClusterTimeoutsModel{Create: types.StringValue("30m")}}, false},
+		{"wait_for", ClusterResourceModel{K3dConfig: types.StringValue("apiVersion: k3d.io/v1alpha4\nkind: Simple\n"), WaitFor: &ClusterWaitForModel{Deployments: []string{"kube-system/coredns"}}}, false},
+		{"invalid wait_for", ClusterResourceModel{K3dConfig: types.StringValue("apiVersion: k3d.io/v1alpha4\nkind: Simple\n"), WaitFor: &ClusterWaitForModel{Deployments: []string{"coredns"}}}, true},
+		{"invalid timeouts", ClusterResourceModel{K3dConfig: types.StringValue("apiVersion: k3d.io/v1alpha4\nkind: Simple\n"), Timeouts: &ClusterTimeoutsModel{Delete: types.StringValue("soon")}}, true},
+		{"switch_context", ClusterResourceModel{K3dConfig: types.StringValue("apiVersion: k3d.io/v1alpha4\nkind: Simple\n"), MergeDefaultKubeconfig: types.BoolValue(true), SwitchContext: types.BoolValue(true)}, false},
+		{"api_host_override", ClusterResourceModel{K3dConfig: types.StringValue("apiVersion: k3d.io/v1alpha4\nkind: Simple\n"), APIHostOverride: types.StringValue("host.docker.internal:6443")}, false},
+		{"invalid api_host_override", ClusterResourceModel{K3dConfig: types.StringValue("apiVersion: k3d.io/v1alpha4\nkind: Simple\n"), APIHostOverride: types.StringValue("https://host.docker.internal")}, true},
+		{"on_destroy stop", ClusterResourceModel{K3dConfig: types.StringValue("apiVersion: k3d.io/v1alpha4\nkind: Simple\n"), OnDestroy: types.StringValue("stop")}, false},
+		{"invalid on_destroy", ClusterResourceModel{K3dConfig: types.StringValue("apiVersion: k3d.io/v1alpha4\nkind: Simple\n"), OnDestroy: types.StringValue("keep")}, true},
+		{"switch_context without merge", ClusterResourceModel{K3dConfig: types.StringValue("apiVersion: k3d.io/v1alpha4\nkind: Simple\n"), SwitchContext: types.BoolValue(true)}, true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			c.data.Name = types.StringValue("test")
+			plan := newTestPlan(t, testResourceSchema(t, &ClusterResource{}), c.data)
+			req := fwresource.ValidateConfigRequest{Config: tfsdk.Config{Schema: plan.Schema, Raw: plan.Raw}}
+			resp := &fwresource.ValidateConfigResponse{}
+			(&ClusterResource{}).ValidateConfig(context.Background(), req, resp)
+
+			if resp.Diagnostics.HasError() != c.wantErr {
+				t.Errorf("expected error %t, got %v", c.wantErr, resp.Diagnostics)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Default cluster operation timeouts. Creating a cluster includes pulling the
+// k3s images, which can take several minutes on slow connections.
+const (
+	defaultClusterCreateTimeout = 20 * time.Minute
+	defaultClusterReadTimeout   = 5 * time.Minute
+	defaultClusterUpdateTimeout = 20 * time.Minute
+	defaultClusterDeleteTimeout = 10 * time.Minute
+)
+
+// ClusterTimeoutsModel describes the timeouts of cluster operations.
+type ClusterTimeoutsModel struct {
+	Create types.String `tfsdk:"create"`
+	Read   types.String `tfsdk:"read"`
+	Update types.String `tfsdk:"update"`
+	Delete types.String `tfsdk:"delete"`
+}
+
+// clusterTimeoutsBlock returns the `timeouts` block, which is a block like in
+// other providers, such as `timeouts { create = "30m" }`.
+func clusterTimeoutsBlock() tfsdk.Block {
+	timeout := func(operation string, fallback time.Duration) tfsdk.Attribute {
+		return tfsdk.Attribute{
+			MarkdownDescription: fmt.Sprintf("Timeout for %s the cluster as a duration such as `10m` or `90s`. Defaults to `%s`.",
+				operation, formatTimeout(fallback)),
+			Type:     types.StringType,
+			Optional: true,
+		}
+	}
+
+	return tfsdk.Block{
+		MarkdownDescription: "Timeouts of cluster operations. " +
+			"k3d is stopped when an operation does not finish in time, for example because the Docker daemon hangs.",
+		NestingMode: tfsdk.BlockNestingModeSingle,
+		Attributes: map[string]tfsdk.Attribute{
+			"create": timeout("creating", defaultClusterCreateTimeout),
+			"read":   timeout("reading", defaultClusterReadTimeout),
+			"update": timeout("scaling or starting", defaultClusterUpdateTimeout),
+			"delete": timeout("deleting", defaultClusterDeleteTimeout),
+		},
+	}
+}
+
+// CreateTimeout returns the create timeout, or the default when it is not set.
+func (m *ClusterTimeoutsModel) CreateTimeout() time.Duration {
+	if m == nil {
+		return defaultClusterCreateTimeout
+	}
+	return parseTimeout(m.Create, defaultClusterCreateTimeout)
+}
+
+// ReadTimeout returns the read timeout, or the default when it is not set.
+func (m *ClusterTimeoutsModel) ReadTimeout() time.Duration {
+	if m == nil {
+		return defaultClusterReadTimeout
+	}
+	return parseTimeout(m.Read, defaultClusterReadTimeout)
+}
+
+// UpdateTimeout returns the update timeout, or the default when it is not set.
+func (m *ClusterTimeoutsModel) UpdateTimeout() time.Duration {
+	if m == nil {
+		return defaultClusterUpdateTimeout
+	}
+	return parseTimeout(m.Update, defaultClusterUpdateTimeout)
+}
+
+// DeleteTimeout returns the delete timeout, or the default when it is not set.
+func (m *ClusterTimeoutsModel) DeleteTimeout() time.Duration {
+	if m == nil {
+		return defaultClusterDeleteTimeout
+	}
+	return parseTimeout(m.Delete, defaultClusterDeleteTimeout)
+}
+
+// parseTimeout parses a timeout value, returning fallback when the value is
+// not set. Invalid values are rejected during validation.
+func parseTimeout(value types.String, fallback time.Duration) time.Duration {
+	if value.IsNull() || value.IsUnknown() {
+		return fallback
+	}
+	timeout, err := time.ParseDuration(value.ValueString())
+	if err != nil || timeout <= 0 {
+		return fallback
+	}
+	return timeout
+}
+
+// formatTimeout formats whole minutes the way they are usually configured,
+// such as `20m` instead of `20m0s`.
+func formatTimeout(timeout time.Duration) string {
+	if timeout%time.Minute == 0 {
+		return fmt.Sprintf("%dm", timeout/time.Minute)
+	}
+	return timeout.String()
+}
+
+// validateClusterTimeouts checks that the configured timeouts are positive
+// durations.
+func validateClusterTimeouts(ctx context.Context, config tfsdk.Config) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	var object types.Object
+	diags.Append(config.GetAttribute(ctx, path.Root("timeouts"), &object)...)
+	if diags.HasError() || object.IsNull() || object.IsUnknown() {
+		return diags
+	}
+	var timeouts ClusterTimeoutsModel
+	diags.Append(object.As(ctx, &timeouts, types.ObjectAsOptions{})...)
+	if diags.HasError() {
+		return diags
+	}
+
+	values := map[string]types.String{
+		"create": timeouts.Create,
+		"read":   timeouts.Read,
+		"update": timeouts.Update,
+		"delete": timeouts.Delete,
+	}
+	for _, name := range []string{"create", "read", "update", "delete"} {
+		value := values[name]
+		if value.IsNull() || value.IsUnknown() {
+			continue
+		}
+		if timeout, err := time.ParseDuration(value.ValueString()); err != nil || timeout <= 0 {
+			diags.AddAttributeError(
+				path.Root("timeouts").AtName(name),
+				"Invalid timeout",
+				fmt.Sprintf("Expected a positive duration such as \"10m\", got: %q.", value.ValueString()))
+		}
+	}
+	return diags
+}
@@ -0,0 +1,48 @@
+package provider
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestClusterTimeouts(t *testing.T) {
+	var unset *ClusterTimeoutsModel
+	if got := unset.CreateTimeout(); got != defaultClusterCreateTimeout {
+		t.Errorf("expected default create timeout, got %s", got)
+	}
+
+	timeouts := &ClusterTimeoutsModel{
+		Create: types.StringValue("45m"),
+		Read:   types.StringNull(),
+		Delete: types.StringValue("90s"),
+	}
+	if got := timeouts.CreateTimeout(); got != 45*time.Minute {
+		t.Errorf("expected create timeout 45m, got %s", got)
+	}
+	if got := timeouts.ReadTimeout(); got != defaultClusterReadTimeout {
+		t.Errorf("expected default read timeout, got %s", got)
+	}
+	if got := timeouts.DeleteTimeout(); got != 90*time.Second {
+		t.Errorf("expected delete timeout 90s, got %s", got)
+	}
+}
+
+func TestClusterTimeoutsBlock(t *testing.T) {
+	schema := testResourceSchema(t, &ClusterResource{})
+
+	// Timeouts are configured as `timeouts { create = "30m" }` like in other
+	// providers.
+	block, ok := schema.Blocks["timeouts"]
+	if !ok {
+		t.Fatal("expected timeouts block")
+	}
+	if block.NestingMode != tfsdk.BlockNestingModeSingle {
+		t.Errorf("expected single nested block, got %v", block.NestingMode)
+	}
+	if _, ok := schema.Attributes["timeouts"]; ok {
+		t.Error("expected timeouts not to be an attribute")
+	}
+}
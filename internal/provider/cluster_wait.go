@@ -0,0 +1,302 @@
+package provider
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// clusterWaitInterval is the interval between checks whether the cluster is
+// ready.
+const clusterWaitInterval = 2 * time.Second
+
+// ClusterWaitForModel describes what to wait for before a created cluster is
+// reported as ready.
+type ClusterWaitForModel struct {
+	Nodes       types.Bool `tfsdk:"nodes"`
+	Deployments []string   `tfsdk:"deployments"`
+}
+
+// clusterWaitForBlock returns the `wait_for` block, such as
+// `wait_for { deployments = ["kube-system/coredns"] }`.
+func clusterWaitForBlock() tfsdk.Block {
+	return tfsdk.Block{
+		MarkdownDescription: "Wait until the cluster is ready before creating it completes, " +
+			"so resources of the Kubernetes and Helm providers can use it right away. " +
+			"Waiting is limited by the `create` timeout.",
+		NestingMode: tfsdk.BlockNestingModeSingle,
+		Attributes: map[string]tfsdk.Attribute{
+			"nodes": {
+				MarkdownDescription: "Wait until all nodes are ready. Defaults to `true`.",
+				Type:                types.BoolType,
+				Optional:            true,
+			},
+			"deployments": {
+				MarkdownDescription: "Deployments to wait for until all their replicas are ready, " +
+					"as `namespace/name` such as `kube-system/coredns` or `kube-system/traefik`.",
+				Type:     types.ListType{ElemType: types.StringType},
+				Optional: true,
+			},
+		},
+	}
+}
+
+// validateClusterWaitFor checks that the deployments to wait for are in the
+// `namespace/name` format.
+func validateClusterWaitFor(ctx context.Context, config tfsdk.Config) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	var deployments types.List
+	diags.Append(config.GetAttribute(ctx, path.Root("wait_for").AtName("deployments"), &deployments)...)
+	if diags.HasError() || deployments.IsNull() || deployments.IsUnknown() {
+		return diags
+	}
+
+	for i, element := range deployments.Elements() {
+		deployment, ok := element.(types.String)
+		if !ok || deployment.IsNull() || deployment.IsUnknown() {
+			continue
+		}
+		if _, _, err := splitDeploymentName(deployment.ValueString()); err != nil {
+			diags.AddAttributeError(
+				path.Root("wait_for").AtName("deployments").AtListIndex(i),
+				"Invalid deployment",
+				fmt.Sprintf("Expected a deployment as namespace/name such as \"kube-system/coredns\", got: %q.", deployment.ValueString()))
+		}
+	}
+	return diags
+}
+
+// splitDeploymentName splits a deployment in the `namespace/name` format.
+func splitDeploymentName(deployment string) (string, string, error) {
+	parts := strings.Split(deployment, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid deployment %q", deployment)
+	}
+	return parts[0], parts[1], nil
+}
+
+// waitForCluster polls the Kubernetes API of the cluster until everything in
+// waitFor is ready or the context is done.
+func waitForCluster(ctx context.Context, client *kubeAPIClient, waitFor ClusterWaitForModel, interval time.Duration) error {
+	var pending []string
+	var checkErr error
+	for {
+		current, err := client.pending(ctx, waitFor)
+		// A check interrupted by the context keeps the result of the last
+		// completed check for the error.
+		if ctx.Err() == nil {
+			pending, checkErr = current, err
+			if err == nil && len(pending) == 0 {
+				return nil
+			}
+			// The API server may not accept connections right after creating
+			// the cluster, so failed checks are retried.
+			tflog.Debug(ctx, "waiting for cluster", map[string]interface{}{"pending": pending, "error": fmt.Sprint(err)})
+		}
+
+		select {
+		case <-ctx.Done():
+			switch {
+			case checkErr != nil:
+				return fmt.Errorf("%w, last check failed: %s", ctx.Err(), checkErr)
+			case len(pending) > 0:
+				return fmt.Errorf("%w, still waiting for %s", ctx.Err(), strings.Join(pending, ", "))
+			default:
+				return ctx.Err()
+			}
+		case <-time.After(interval):
+		}
+	}
+}
+
+// kubeAPIClient is a minimal client of the Kubernetes API, authenticated with
+// the credentials of the cluster kubeconfig.
+type kubeAPIClient struct {
+	host   string
+	token  string
+	client *http.Client
+}
+
+// newKubeAPIClient returns a client of the Kubernetes API at host, using the
+// base64 encoded PEM certificates and the bearer token of a kubeconfig. The
+// client certificate and the token are optional.
+func newKubeAPIClient(host string, caData string, certData string, keyData string, token string) (*kubeAPIClient, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if caData != "" {
+		ca, err := base64.StdEncoding.DecodeString(caData)
+		if err != nil {
+			return nil, fmt.Errorf("failed decoding cluster CA certificate: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, errors.New("failed parsing cluster CA certificate")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if certData != "" && keyData != "" {
+		cert, err := base64.StdEncoding.DecodeString(certData)
+		if err != nil {
+			return nil, fmt.Errorf("failed decoding client certificate: %w", err)
+		}
+		key, err := base64.StdEncoding.DecodeString(keyData)
+		if err != nil {
+			return nil, fmt.Errorf("failed decoding client key: %w", err)
+		}
+		pair, err := tls.X509KeyPair(cert, key)
+		if err != nil {
+			return nil, fmt.Errorf("failed parsing client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{pair}
+	}
+
+	return &kubeAPIClient{
+		host:  strings.TrimSuffix(host, "/"),
+		token: token,
+		client: &http.Client{
+			Transport: &http.Transport{TLSClientConfig: tlsConfig},
+			Timeout:   10 * time.Second,
+		},
+	}, nil
+}
+
+// errKubeAPINotFound is returned when a Kubernetes object does not exist.
+var errKubeAPINotFound = errors.New("not found")
+
+// get gets the Kubernetes API path and decodes the JSON response into v.
+func (c *kubeAPIClient) get(ctx context.Context, path string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.host+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return errKubeAPINotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("GET %s returned %s: %s", path, resp.Status, strings.TrimSpace(string(body)))
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// pending returns the nodes and deployments of waitFor which are not ready.
+func (c *kubeAPIClient) pending(ctx context.Context, waitFor ClusterWaitForModel) ([]string, error) {
+	pending := []string{}
+
+	if waitFor.Nodes.IsNull() || waitFor.Nodes.ValueBool() {
+		var nodes kubeNodeList
+		if err := c.get(ctx, "/api/v1/nodes", &nodes); err != nil {
+			return nil, err
+		}
+		if len(nodes.Items) == 0 {
+			pending = append(pending, "nodes")
+		}
+		for _, node := range nodes.Items {
+			if !node.Ready() {
+				pending = append(pending, "node "+node.Metadata.Name)
+			}
+		}
+	}
+
+	for _, deployment := range waitFor.Deployments {
+		namespace, name, err := splitDeploymentName(deployment)
+		if err != nil {
+			return nil, err
+		}
+		var object kubeDeployment
+		err = c.get(ctx, fmt.Sprintf("/apis/apps/v1/namespaces/%s/deployments/%s", namespace, name), &object)
+		// Deployments of k3s add-ons such as traefik are created some time
+		// after the API server is ready.
+		if errors.Is(err, errKubeAPINotFound) {
+			pending = append(pending, "deployment "+deployment)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !object.Ready() {
+			pending = append(pending, "deployment "+deployment)
+		}
+	}
+	return pending, nil
+}
+
+type kubeObjectMetadata struct {
+	Name       string `json:"name"`
+	Generation int64  `json:"generation"`
+}
+
+type kubeNodeList struct {
+	Items []kubeNode `json:"items"`
+}
+
+type kubeNode struct {
+	Metadata kubeObjectMetadata `json:"metadata"`
+	Status   struct {
+		Conditions []struct {
+			Type   string `json:"type"`
+			Status string `json:"status"`
+		} `json:"conditions"`
+	} `json:"status"`
+}
+
+// Ready reports whether the node has the Ready condition.
+func (n kubeNode) Ready() bool {
+	for _, condition := range n.Status.Conditions {
+		if condition.Type == "Ready" {
+			return condition.Status == "True"
+		}
+	}
+	return false
+}
+
+type kubeDeployment struct {
+	Metadata kubeObjectMetadata `json:"metadata"`
+	Spec     struct {
+		Replicas *int64 `json:"replicas"`
+	} `json:"spec"`
+	Status struct {
+		ObservedGeneration int64 `json:"observedGeneration"`
+		UpdatedReplicas    int64 `json:"updatedReplicas"`
+		AvailableReplicas  int64 `json:"availableReplicas"`
+	} `json:"status"`
+}
+
+// Ready reports whether the deployment rolled out all its replicas, the way
+// `kubectl rollout status` does.
+func (d kubeDeployment) Ready() bool {
+	replicas := int64(1)
+	if d.Spec.Replicas != nil {
+		replicas = *d.Spec.Replicas
+	}
+	return d.Status.ObservedGeneration >= d.Metadata.Generation &&
+		d.Status.UpdatedReplicas >= replicas &&
+		d.Status.AvailableReplicas >= replicas
+}
@@ -0,0 +1,138 @@
+package provider
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+const testNodeList = `{"items": [
+	{"metadata": {"name": "k3d-test-server-0"}, "status": {"conditions": [{"type": "Ready", "status": "True"}]}},
+	{"metadata": {"name": "k3d-test-agent-0"}, "status": {"conditions": [{"type": "Ready", "status": "%s"}]}}
+]}`
+
+const testDeployment = `{
+	"metadata": {"name": "coredns", "generation": 1},
+	"spec": {"replicas": 1},
+	"status": {"observedGeneration": 1, "updatedReplicas": 1, "availableReplicas": %d}
+}`
+
+// newTestKubeAPIServer returns a Kubernetes API server serving responses by
+// path, and a client of it.
+func newTestKubeAPIServer(t *testing.T, responses map[string]string) *kubeAPIClient {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response, ok := responses[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(response))
+	}))
+	t.Cleanup(server.Close)
+
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	client, err := newKubeAPIClient(server.URL, base64.StdEncoding.EncodeToString(ca), "", "", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return client
+}
+
+func TestWaitForCluster(t *testing.T) {
+	client := newTestKubeAPIServer(t, map[string]string{
+		"/api/v1/nodes": strings.Replace(testNodeList, "%s", "True", 1),
+		"/apis/apps/v1/namespaces/kube-system/deployments/coredns": strings.Replace(testDeployment, "%d", "1", 1),
+	})
+
+	waitFor := ClusterWaitForModel{Nodes: types.BoolNull(), Deployments: []string{"kube-system/coredns"}}
+	if err := waitForCluster(context.Background(), client, waitFor, time.Millisecond); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestWaitForClusterPending(t *testing.T) {
+	client := newTestKubeAPIServer(t, map[string]string{
+		"/api/v1/nodes": strings.Replace(testNodeList, "%s", "False", 1),
+		"/apis/apps/v1/namespaces/kube-system/deployments/coredns": strings.Replace(testDeployment, "%d", "0", 1),
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	waitFor := ClusterWaitForModel{Nodes: types.BoolValue(true), Deployments: []string{"kube-system/coredns", "kube-system/traefik"}}
+	err := waitForCluster(ctx, client, waitFor, time.Millisecond)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded error, got %v", err)
+	}
+	for _, want := range []string{"node k3d-test-agent-0", "deployment kube-system/coredns", "deployment kube-system/traefik"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error to mention %s, got %v", want, err)
+		}
+	}
+	if strings.Contains(err.Error(), "k3d-test-server-0") {
+		t.Errorf("expected ready node not to be mentioned, got %v", err)
+	}
+}
+
+func TestWaitForClusterNodesDisabled(t *testing.T) {
+	// Nodes are not checked, so the missing node list is not requested.
+	client := newTestKubeAPIServer(t, map[string]string{})
+
+	waitFor := ClusterWaitForModel{Nodes: types.BoolValue(false)}
+	if err := waitForCluster(context.Background(), client, waitFor, time.Millisecond); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestSplitDeploymentName(t *testing.T) {
+	namespace, name, err := splitDeploymentName("kube-system/coredns")
+	if err != nil || namespace != "kube-system" || name != "coredns" {
+		t.Errorf("expected kube-system and coredns, got %q, %q, %v", namespace, name, err)
+	}
+	for _, invalid := range []string{"coredns", "/coredns", "kube-system/", "a/b/c"} {
+		if _, _, err := splitDeploymentName(invalid); err == nil {
+			t.Errorf("expected error for %q", invalid)
+		}
+	}
+}
+
+func TestKubeAPIClientToken(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(strings.Replace(testNodeList, "%s", "True", 1)))
+	}))
+	t.Cleanup(server.Close)
+
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	client, err := newKubeAPIClient(server.URL, base64.StdEncoding.EncodeToString(ca), "", "", "secret")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var nodes kubeNodeList
+	if err := client.get(context.Background(), "/api/v1/nodes", &nodes); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestClusterWaitForBlock(t *testing.T) {
+	schema := testResourceSchema(t, &ClusterResource{})
+
+	block, ok := schema.Blocks["wait_for"]
+	if !ok {
+		t.Fatal("expected wait_for block")
+	}
+	if block.NestingMode != tfsdk.BlockNestingModeSingle {
+		t.Errorf("expected single nested block, got %v", block.NestingMode)
+	}
+}
@@ -0,0 +1,224 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces
+var _ datasource.DataSource = &ClustersDataSource{}
+var _ datasource.DataSourceWithConfigure = &ClustersDataSource{}
+
+func NewClustersDataSource() datasource.DataSource {
+	return &ClustersDataSource{}
+}
+
+// ClustersDataSource defines the data source implementation.
+type ClustersDataSource struct {
+	runner K3dRunner
+}
+
+// ClustersDataSourceModel describes the data source data model.
+type ClustersDataSourceModel struct {
+	ID       types.String                     `tfsdk:"id"`
+	Clusters []ClustersDataSourceClusterModel `tfsdk:"clusters"`
+}
+
+// ClustersDataSourceClusterModel describes a cluster in the data source data
+// model.
+type ClustersDataSourceClusterModel struct {
+	Name            types.String                  `tfsdk:"name"`
+	Running         types.Bool                    `tfsdk:"running"`
+	ServersCount    types.Int64                   `tfsdk:"servers_count"`
+	ServersRunning  types.Int64                   `tfsdk:"servers_running"`
+	AgentsCount     types.Int64                   `tfsdk:"agents_count"`
+	AgentsRunning   types.Int64                   `tfsdk:"agents_running"`
+	Image           types.String                  `tfsdk:"image"`
+	Network         types.String                  `tfsdk:"network"`
+	HasLoadbalancer types.Bool                    `tfsdk:"has_loadbalancer"`
+	Nodes           []ClustersDataSourceNodeModel `tfsdk:"nodes"`
+}
+
+// ClustersDataSourceNodeModel describes a cluster node in the data source
+// data model.
+type ClustersDataSourceNodeModel struct {
+	Name    types.String `tfsdk:"name"`
+	Role    types.String `tfsdk:"role"`
+	Image   types.String `tfsdk:"image"`
+	Running types.Bool   `tfsdk:"running"`
+	Status  types.String `tfsdk:"status"`
+}
+
+func (d *ClustersDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_clusters"
+}
+
+func (d *ClustersDataSource) GetSchema(ctx context.Context) (tfsdk.Schema, diag.Diagnostics) {
+	return tfsdk.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "The data source `k3d_clusters` lists all k3d clusters with the status of their nodes.",
+
+		Attributes: map[string]tfsdk.Attribute{
+			"id": {
+				MarkdownDescription: "Used internally by the provider.",
+				Type:                types.StringType,
+				Computed:            true,
+			},
+			"clusters": {
+				MarkdownDescription: "All k3d clusters.",
+				Computed:            true,
+				Attributes: tfsdk.ListNestedAttributes(map[string]tfsdk.Attribute{
+					"name": {
+						MarkdownDescription: "Cluster name.",
+						Type:                types.StringType,
+						Computed:            true,
+					},
+					"running": {
+						MarkdownDescription: "Whether all server nodes of the cluster are running.",
+						Type:                types.BoolType,
+						Computed:            true,
+					},
+					"servers_count": {
+						MarkdownDescription: "Amount of server nodes.",
+						Type:                types.Int64Type,
+						Computed:            true,
+					},
+					"servers_running": {
+						MarkdownDescription: "Amount of running server nodes.",
+						Type:                types.Int64Type,
+						Computed:            true,
+					},
+					"agents_count": {
+						MarkdownDescription: "Amount of agent nodes.",
+						Type:                types.Int64Type,
+						Computed:            true,
+					},
+					"agents_running": {
+						MarkdownDescription: "Amount of running agent nodes.",
+						Type:                types.Int64Type,
+						Computed:            true,
+					},
+					"image": {
+						MarkdownDescription: "K3s image of the server nodes.",
+						Type:                types.StringType,
+						Computed:            true,
+					},
+					"network": {
+						MarkdownDescription: "Docker network of the cluster.",
+						Type:                types.StringType,
+						Computed:            true,
+					},
+					"has_loadbalancer": {
+						MarkdownDescription: "Whether the cluster has a load balancer node.",
+						Type:                types.BoolType,
+						Computed:            true,
+					},
+					"nodes": {
+						MarkdownDescription: "Cluster nodes.",
+						Computed:            true,
+						Attributes: tfsdk.ListNestedAttributes(map[string]tfsdk.Attribute{
+							"name": {
+								MarkdownDescription: "Node container name.",
+								Type:                types.StringType,
+								Computed:            true,
+							},
+							"role": {
+								MarkdownDescription: "Node role, such as `server`, `agent`, `loadbalancer` or `registry`.",
+								Type:                types.StringType,
+								Computed:            true,
+							},
+							"image": {
+								MarkdownDescription: "Node image.",
+								Type:                types.StringType,
+								Computed:            true,
+							},
+							"running": {
+								MarkdownDescription: "Whether the node is running.",
+								Type:                types.BoolType,
+								Computed:            true,
+							},
+							"status": {
+								MarkdownDescription: "Node container status, such as `running` or `exited`.",
+								Type:                types.StringType,
+								Computed:            true,
+							},
+						}),
+					},
+				}),
+			},
+		},
+	}, nil
+}
+
+func (d *ClustersDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	runner, ok := req.ProviderData.(K3dRunner)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected K3dRunner, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.runner = runner
+}
+
+func (d *ClustersDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data ClustersDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	clusters, diags := listClusters(ctx, d.runner)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.ID = types.StringValue("k3d_clusters")
+	data.Clusters = []ClustersDataSourceClusterModel{}
+	for _, cluster := range clusters {
+		nodes := []ClustersDataSourceNodeModel{}
+		for _, node := range cluster.Nodes {
+			nodes = append(nodes, ClustersDataSourceNodeModel{
+				Name:    types.StringValue(node.Name),
+				Role:    types.StringValue(node.Role),
+				Image:   types.StringValue(node.Image),
+				Running: types.BoolValue(node.State.Running),
+				Status:  types.StringValue(node.State.Status),
+			})
+		}
+
+		data.Clusters = append(data.Clusters, ClustersDataSourceClusterModel{
+			Name:            types.StringValue(cluster.Name),
+			Running:         types.BoolValue(cluster.Running()),
+			ServersCount:    types.Int64Value(int64(cluster.ServersCount)),
+			ServersRunning:  types.Int64Value(int64(cluster.ServersRunning)),
+			AgentsCount:     types.Int64Value(int64(cluster.AgentsCount)),
+			AgentsRunning:   types.Int64Value(int64(cluster.AgentsRunning)),
+			Image:           types.StringValue(cluster.Image()),
+			Network:         types.StringValue(cluster.Network.Name),
+			HasLoadbalancer: types.BoolValue(cluster.HasLoadbalancer),
+			Nodes:           nodes,
+		})
+	}
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -0,0 +1,126 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccClustersDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Read testing
+			{
+				Config: testAccClustersDataSourceConfig(),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckTypeSetElemNestedAttrs("data.k3d_clusters.test", "clusters.*", map[string]string{
+						"name":             "k3d-provider-test",
+						"running":          "true",
+						"servers_count":    "1",
+						"agents_count":     "1",
+						"has_loadbalancer": "true",
+					}),
+				),
+			},
+		},
+	})
+}
+
+func testAccClustersDataSourceConfig() string {
+	return `
+resource "k3d_cluster" "test" {
+	name = "k3d-provider-test"
+  k3d_config = <<EOF
+apiVersion: k3d.io/v1alpha4
+kind: Simple
+agents: 1
+EOF
+}
+
+data "k3d_clusters" "test" {
+	depends_on = [resource.k3d_cluster.test]
+}
+`
+}
+
+const testClusterListDetailed = `[
+	{
+		"name": "test",
+		"network": {"name": "k3d-test", "id": "c0ffee", "external": false},
+		"serversCount": 1,
+		"serversRunning": 1,
+		"agentsCount": 1,
+		"agentsRunning": 0,
+		"hasLoadbalancer": true,
+		"nodes": [
+			{"name": "k3d-test-server-0", "role": "server", "image": "rancher/k3s:v1.24.4-k3s1", "State": {"Running": true, "Status": "running"}},
+			{"name": "k3d-test-agent-0", "role": "agent", "image": "rancher/k3s:v1.24.4-k3s1", "State": {"Running": false, "Status": "exited"}},
+			{"name": "k3d-test-serverlb", "role": "loadbalancer", "image": "ghcr.io/k3d-io/k3d-proxy:5.4.6", "State": {"Running": true, "Status": "running"}}
+		]
+	},
+	{"name": "other", "serversCount": 1, "serversRunning": 0}
+]`
+
+func TestClustersDataSourceRead(t *testing.T) {
+	runner := (&fakeK3dRunner{}).
+		On(testClusterListDetailed, nil, "cluster", "list")
+	d := &ClustersDataSource{runner: runner}
+
+	schema, diags := d.GetSchema(context.Background())
+	if diags.HasError() {
+		t.Fatalf("unexpected schema error: %v", diags)
+	}
+	state := tfsdk.State{
+		Schema: schema,
+		Raw:    tftypes.NewValue(schema.Type().TerraformType(context.Background()), nil),
+	}
+	config := state
+	if diags := config.Set(context.Background(), &ClustersDataSourceModel{}); diags.HasError() {
+		t.Fatalf("unexpected config error: %v", diags)
+	}
+	resp := &datasource.ReadResponse{State: state}
+	d.Read(context.Background(), datasource.ReadRequest{Config: tfsdk.Config{Schema: schema, Raw: config.Raw}}, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+	var data ClustersDataSourceModel
+	if diags := resp.State.Get(context.Background(), &data); diags.HasError() {
+		t.Fatalf("unexpected state error: %v", diags)
+	}
+	if len(data.Clusters) != 2 {
+		t.Fatalf("expected 2 clusters, got %d", len(data.Clusters))
+	}
+
+	cluster := data.Clusters[0]
+	if got := cluster.Name.ValueString(); got != "test" {
+		t.Errorf("expected name test, got %s", got)
+	}
+	if got := cluster.Network.ValueString(); got != "k3d-test" {
+		t.Errorf("expected network k3d-test, got %s", got)
+	}
+	if got := cluster.Image.ValueString(); got != "rancher/k3s:v1.24.4-k3s1" {
+		t.Errorf("expected image rancher/k3s:v1.24.4-k3s1, got %s", got)
+	}
+	if cluster.AgentsCount.ValueInt64() != 1 || cluster.AgentsRunning.ValueInt64() != 0 {
+		t.Errorf("expected 1 agent with 0 running, got %s with %s running", cluster.AgentsCount, cluster.AgentsRunning)
+	}
+	if !cluster.HasLoadbalancer.ValueBool() {
+		t.Error("expected has_loadbalancer to be true")
+	}
+	if len(cluster.Nodes) != 3 {
+		t.Fatalf("expected 3 nodes, got %d", len(cluster.Nodes))
+	}
+	if got := cluster.Nodes[1].Status.ValueString(); got != "exited" {
+		t.Errorf("expected agent status exited, got %s", got)
+	}
+	if data.Clusters[1].Running.ValueBool() {
+		t.Error("expected stopped cluster not to be running")
+	}
+}
@@ -0,0 +1,140 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+)
+
+// k3dErrorKind classifies why a k3d command failed.
+type k3dErrorKind int
+
+const (
+	k3dErrorUnknown k3dErrorKind = iota
+	k3dErrorNotInstalled
+	k3dErrorPermissionDenied
+	k3dErrorDockerUnavailable
+	k3dErrorAlreadyExists
+	k3dErrorSchemaValidation
+	k3dErrorTimeout
+	k3dErrorCanceled
+)
+
+// classifyK3dError classifies a failed k3d command by its error and output.
+func classifyK3dError(output []byte, err error) k3dErrorKind {
+	text := string(output)
+	if err != nil {
+		text += "\n" + err.Error()
+	}
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return k3dErrorTimeout
+	case errors.Is(err, context.Canceled):
+		return k3dErrorCanceled
+	case errors.Is(err, exec.ErrNotFound) || strings.Contains(text, "executable file not found"):
+		return k3dErrorNotInstalled
+	case strings.Contains(text, "permission denied"):
+		return k3dErrorPermissionDenied
+	case strings.Contains(text, "Cannot connect to the Docker daemon"):
+		return k3dErrorDockerUnavailable
+	case strings.Contains(text, "already exists"):
+		return k3dErrorAlreadyExists
+	case strings.Contains(text, "Schema Validation failed"):
+		return k3dErrorSchemaValidation
+	default:
+		return k3dErrorUnknown
+	}
+}
+
+// k3dObject is the object a k3d command acts on, so errors name the right
+// kind of object and refer to the attributes configuring it.
+type k3dObject struct {
+	// Kind is the k3d command of the object, such as cluster or node.
+	Kind string
+	// Name is the name k3d knows the object by.
+	Name string
+	// NamePath is the attribute configuring the name, if any.
+	NamePath path.Path
+	// ConfigPath is the attribute holding the k3d config, if any.
+	ConfigPath path.Path
+}
+
+// addK3dError adds a diagnostic for a failed k3d command on object. Failures
+// that are not classified use summary.
+func addK3dError(diags *diag.Diagnostics, summary string, object k3dObject, output []byte, err error) {
+	details := k3dErrorDetails(output, err)
+
+	switch classifyK3dError(output, err) {
+	case k3dErrorNotInstalled:
+		diags.AddError(
+			"k3d is not installed",
+			"The k3d binary could not be found. "+
+				"Install k3d, see the installation guide at https://k3d.io/v5.4.6/#installation, "+
+				"or set the provider `k3d_path` attribute to the location of the binary.\n\n"+details)
+	case k3dErrorPermissionDenied:
+		diags.AddError(
+			"Permission denied accessing Docker",
+			"k3d is not allowed to access the Docker daemon. "+
+				"Add your user to the `docker` group, run Terraform with `sudo`, "+
+				"or set the provider `docker_host` attribute to a Docker daemon you can access, such as rootless Docker.\n\n"+details)
+	case k3dErrorDockerUnavailable:
+		diags.AddError(
+			"Docker daemon is not reachable",
+			"k3d could not connect to the Docker daemon. "+
+				"Make sure Docker is running, or set the provider `docker_host` attribute to the address of the daemon.\n\n"+details)
+	case k3dErrorAlreadyExists:
+		if object.Kind == "" {
+			diags.AddError(summary, details)
+			return
+		}
+		title := strings.ToUpper(object.Kind[:1]) + object.Kind[1:] + " already exists"
+		deleteCommand := fmt.Sprintf("`k3d %s delete %s`", object.Kind, object.Name)
+		if len(object.NamePath.Steps()) == 0 {
+			diags.AddError(title, fmt.Sprintf("A k3d %s named %q already exists. "+
+				"Delete the existing %s with %s.\n\n", object.Kind, object.Name, object.Kind, deleteCommand)+details)
+			return
+		}
+		diags.AddAttributeError(object.NamePath, title, fmt.Sprintf("A k3d %s named %q already exists. "+
+			"Choose another name, import the existing %s with `terraform import`, or delete it with %s.\n\n",
+			object.Kind, object.Name, object.Kind, deleteCommand)+details)
+	case k3dErrorSchemaValidation:
+		if len(object.ConfigPath.Steps()) == 0 {
+			diags.AddError(summary, details)
+			return
+		}
+		diags.AddAttributeError(
+			object.ConfigPath,
+			"Invalid k3d config",
+			"k3d rejected the config because it does not match the k3d config schema. "+
+				"Check the config against the config options in the k3d documentation at "+
+				"https://k3d.io/v5.4.6/usage/configfile/#config-options.\n\n"+details)
+	case k3dErrorTimeout:
+		diags.AddError(
+			"k3d timed out",
+			"k3d did not finish in time and was stopped. "+
+				"Make sure the Docker daemon is responsive, or increase the resource `timeouts` "+
+				"or the provider `command_timeout` attribute.\n\n"+details)
+	case k3dErrorCanceled:
+		diags.AddError(
+			"k3d was canceled",
+			"k3d was stopped because Terraform was interrupted. "+
+				"The cluster may be left partially created or deleted, run Terraform again to reconcile it.\n\n"+details)
+	default:
+		diags.AddError(summary, details)
+	}
+}
+
+// k3dErrorDetails returns the output of a failed k3d command, or the error
+// when the command had no output.
+func k3dErrorDetails(output []byte, err error) string {
+	if text := strings.TrimSpace(string(output)); text != "" {
+		return text
+	}
+	return fmt.Sprint(err)
+}
@@ -0,0 +1,109 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+)
+
+func TestClassifyK3dError(t *testing.T) {
+	exitErr := errors.New("exit status 1")
+	cases := []struct {
+		name   string
+		output string
+		err    error
+		want   k3dErrorKind
+	}{
+		{"unknown", "FATA[0000] something broke", exitErr, k3dErrorUnknown},
+		{"not installed", "", &exec.Error{Name: "k3d", Err: exec.ErrNotFound}, k3dErrorNotInstalled},
+		{"permission denied", "permission denied while trying to connect to the Docker daemon socket", exitErr, k3dErrorPermissionDenied},
+		{"docker unavailable", "Cannot connect to the Docker daemon at unix:///var/run/docker.sock", exitErr, k3dErrorDockerUnavailable},
+		{"already exists", "FATA[0000] Failed to create cluster 'test' because a cluster with that name already exists", exitErr, k3dErrorAlreadyExists},
+		{"schema validation", "FATA[0000] Schema Validation failed for config file", exitErr, k3dErrorSchemaValidation},
+		{"timeout", "INFO[0000] Pulling image", fmt.Errorf("k3d was stopped: %w", context.DeadlineExceeded), k3dErrorTimeout},
+		{"canceled", "", fmt.Errorf("k3d was stopped: %w", context.Canceled), k3dErrorCanceled},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := classifyK3dError([]byte(c.output), c.err); got != c.want {
+				t.Errorf("expected kind %d, got %d", c.want, got)
+			}
+		})
+	}
+}
+
+func TestAddK3dErrorAttributePaths(t *testing.T) {
+	exitErr := errors.New("exit status 1")
+	cases := []struct {
+		output string
+		want   path.Path
+	}{
+		{"FATA[0000] Schema Validation failed for config file", path.Root("k3d_config")},
+		{"FATA[0000] a cluster with that name already exists", path.Root("name")},
+	}
+	for _, c := range cases {
+		var diags diag.Diagnostics
+		addK3dError(&diags, "Failed creating k3d cluster", k3dObject{
+			Kind:       "cluster",
+			Name:       "test",
+			NamePath:   path.Root("name"),
+			ConfigPath: path.Root("k3d_config"),
+		}, []byte(c.output), exitErr)
+
+		if len(diags) != 1 {
+			t.Fatalf("expected 1 diagnostic, got %d", len(diags))
+		}
+		withPath, ok := diags[0].(diag.DiagnosticWithPath)
+		if !ok || !withPath.Path().Equal(c.want) {
+			t.Errorf("expected diagnostic for %s, got %v", c.want, diags[0])
+		}
+	}
+}
+
+func TestAddK3dErrorNode(t *testing.T) {
+	var diags diag.Diagnostics
+	addK3dError(&diags, "Failed creating k3d agent node", k3dObject{Kind: "node", Name: "k3d-test-agent-2-0"},
+		[]byte("FATA[0000] node with that name already exists"), errors.New("exit status 1"))
+
+	if len(diags) != 1 {
+		t.Fatalf("expected 1 diagnostic, got %d", len(diags))
+	}
+	if _, ok := diags[0].(diag.DiagnosticWithPath); ok {
+		t.Errorf("expected diagnostic without attribute, got %v", diags[0])
+	}
+	if diags[0].Summary() != "Node already exists" {
+		t.Errorf("expected node summary, got %q", diags[0].Summary())
+	}
+	if !strings.Contains(diags[0].Detail(), "`k3d node delete k3d-test-agent-2-0`") {
+		t.Errorf("expected node delete command in detail, got %q", diags[0].Detail())
+	}
+
+	// Only cluster configs are validated against the k3d config schema.
+	diags = nil
+	addK3dError(&diags, "Failed creating k3d node", k3dObject{Kind: "node", Name: "k3d-test-agent-2-0"},
+		[]byte("FATA[0000] Schema Validation failed for config file"), errors.New("exit status 1"))
+	if diags[0].Summary() != "Failed creating k3d node" {
+		t.Errorf("expected summary to be kept, got %q", diags[0].Summary())
+	}
+}
+
+func TestAddK3dErrorUnknown(t *testing.T) {
+	var diags diag.Diagnostics
+	addK3dError(&diags, "Failed deleting k3d cluster", k3dObject{Kind: "cluster", Name: "test"}, nil, errors.New("exit status 1"))
+
+	if len(diags) != 1 {
+		t.Fatalf("expected 1 diagnostic, got %d", len(diags))
+	}
+	if diags[0].Summary() != "Failed deleting k3d cluster" {
+		t.Errorf("expected summary to be kept, got %q", diags[0].Summary())
+	}
+	if diags[0].Detail() != "exit status 1" {
+		t.Errorf("expected error as detail without output, got %q", diags[0].Detail())
+	}
+}
@@ -0,0 +1,404 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces
+var _ resource.Resource = &ImageImportResource{}
+var _ resource.ResourceWithModifyPlan = &ImageImportResource{}
+
+func NewImageImportResource() resource.Resource {
+	return &ImageImportResource{}
+}
+
+// ImageImportResource defines the resource implementation.
+type ImageImportResource struct {
+	runner K3dRunner
+	// docker runs the docker CLI to look up the IDs of local images. Changes
+	// of local images are not detected when it is nil or docker is missing.
+	docker DockerRunner
+}
+
+// ImageImportResourceModel describes the resource data model.
+type ImageImportResourceModel struct {
+	ID       types.String `tfsdk:"id"`
+	Cluster  types.String `tfsdk:"cluster"`
+	Images   types.List   `tfsdk:"images"`
+	ImageIDs types.Map    `tfsdk:"image_ids"`
+}
+
+func (r *ImageImportResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_image_import"
+}
+
+func (r *ImageImportResource) GetSchema(ctx context.Context) (tfsdk.Schema, diag.Diagnostics) {
+	return tfsdk.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "The resource `k3d_image_import` imports container images from the host into a k3d cluster " +
+			"with `k3d image import`, so pods can use locally built images without a registry.\n" +
+			"\n" +
+			"The IDs of local images and the checksums of image tarballs are tracked, " +
+			"and images are imported again when they change. " +
+			"The IDs of local images are looked up with the docker CLI. " +
+			"Without it, images are imported when they are added to `images`, but rebuilt images are not detected. " +
+			"Images are also imported again when the cluster is created again, even under the same name. " +
+			"k3d does not support removing imported images, so destroying the resource keeps the images in the cluster.",
+
+		Attributes: map[string]tfsdk.Attribute{
+			"id": {
+				MarkdownDescription: "Used internally by the provider.",
+				Type:                types.StringType,
+				Computed:            true,
+				PlanModifiers: tfsdk.AttributePlanModifiers{
+					resource.UseStateForUnknown(),
+				},
+			},
+			"cluster": {
+				MarkdownDescription: "Name of the cluster to import the images into. Changing the cluster forces replacement.",
+				Type:                types.StringType,
+				Required:            true,
+				PlanModifiers: tfsdk.AttributePlanModifiers{
+					resource.RequiresReplace(),
+				},
+			},
+			"images": {
+				MarkdownDescription: "Image references such as `example/app:dev`, or paths of image tarballs created with `docker save`.",
+				Type:                types.ListType{ElemType: types.StringType},
+				Required:            true,
+			},
+			"image_ids": {
+				MarkdownDescription: "IDs of the imported images by image reference, " +
+					"or SHA-256 checksums of the imported tarballs by path.",
+				Type:     types.MapType{ElemType: types.StringType},
+				Computed: true,
+			},
+		},
+	}, nil
+}
+
+func (r *ImageImportResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	runner, ok := req.ProviderData.(K3dRunner)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected K3dRunner, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.runner = runner
+	r.docker = newExecDockerRunner(runner)
+}
+
+// ModifyPlan plans importing the images again when the local images changed
+// since they were imported.
+func (r *ImageImportResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to do when the resource is destroyed.
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan, state *ImageImportResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if !req.State.Raw.IsNull() {
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	}
+	if resp.Diagnostics.HasError() || plan.Images.IsUnknown() {
+		return
+	}
+
+	var images []string
+	resp.Diagnostics.Append(plan.Images.ElementsAs(ctx, &images, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Images may be built by other resources during apply, so missing images
+	// are not an error while planning.
+	ids, err := r.imageIDs(ctx, images)
+	if errors.Is(err, errDockerUnavailable) && state != nil {
+		imported := map[string]string{}
+		resp.Diagnostics.Append(state.ImageIDs.ElementsAs(ctx, &imported, false)...)
+		keepImportedIDs(ids, imported)
+		err = nil
+	}
+	if err != nil {
+		tflog.Debug(ctx, "failed looking up image IDs while planning", map[string]interface{}{"error": err.Error()})
+		plan.ImageIDs = types.MapUnknown(types.StringType)
+	} else if state == nil || !imageIDsEqual(state.ImageIDs, ids) {
+		plan.ImageIDs = types.MapUnknown(types.StringType)
+	} else {
+		plan.ImageIDs = state.ImageIDs
+	}
+
+	resp.Diagnostics.Append(resp.Plan.Set(ctx, &plan)...)
+}
+
+func (r *ImageImportResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data *ImageImportResourceModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The ID identifies the cluster instance the images are imported into, so
+	// Read notices when the cluster is created again under the same name.
+	clusters, diags := listClusters(ctx, r.runner)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	cluster, err := findCluster(clusters, data.Cluster.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("cluster"),
+			"k3d cluster not found",
+			fmt.Sprintf("A k3d cluster named %q does not exist. List existing clusters with `k3d cluster list`.", data.Cluster.ValueString()))
+		return
+	}
+
+	resp.Diagnostics.Append(r.importImages(ctx, data, map[string]string{})...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	data.ID = types.StringValue(imageImportID(cluster))
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *ImageImportResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data *ImageImportResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The images are gone when the cluster was deleted, even when a cluster
+	// with the same name was created again.
+	clusters, diags := listClusters(ctx, r.runner)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	cluster, err := findCluster(clusters, data.Cluster.ValueString())
+	if err != nil {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if id := imageImportID(cluster); id != data.ID.ValueString() {
+		tflog.Warn(ctx, "cluster was created again, images need to be imported again", map[string]interface{}{"cluster": cluster.Name})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *ImageImportResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data, state *ImageImportResourceModel
+
+	// Read Terraform plan and prior state data into the models
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Only new and changed images are imported again.
+	imported := map[string]string{}
+	resp.Diagnostics.Append(state.ImageIDs.ElementsAs(ctx, &imported, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(r.importImages(ctx, data, imported)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	data.ID = state.ID
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *ImageImportResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// k3d can not remove imported images from the cluster nodes.
+	tflog.Info(ctx, "imported images are kept in the cluster")
+}
+
+// importImages imports the images of data which are not in imported with
+// the same ID, and sets the image IDs on data.
+func (r *ImageImportResource) importImages(ctx context.Context, data *ImageImportResourceModel, imported map[string]string) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	var images []string
+	diags.Append(data.Images.ElementsAs(ctx, &images, false)...)
+	if diags.HasError() {
+		return diags
+	}
+
+	ids, err := r.imageIDs(ctx, images)
+	if errors.Is(err, errDockerUnavailable) {
+		diags.AddAttributeWarning(
+			path.Root("images"),
+			"Image changes are not detected",
+			"The docker CLI is not available to look up the IDs of local images, "+
+				"so images are only imported when they are added to `images`. "+
+				"Install the docker CLI to import rebuilt images again.\n\n"+err.Error())
+		keepImportedIDs(ids, imported)
+		err = nil
+	}
+	if err != nil {
+		diags.AddAttributeError(
+			path.Root("images"),
+			"Failed looking up images",
+			fmt.Sprintf("Make sure the images exist locally, such as with `docker image ls`, and the tarballs exist.\n\n%s", err))
+		return diags
+	}
+
+	var changed []string
+	for _, image := range images {
+		if id, ok := imported[image]; !ok || id != ids[image] {
+			changed = append(changed, image)
+		}
+	}
+
+	if len(changed) > 0 {
+		args := append([]string{"image", "import"}, changed...)
+		args = append(args, "--cluster", data.Cluster.ValueString())
+		output, err := r.runner.Run(withK3dPhase(ctx, "import"), args...)
+		if err != nil {
+			addK3dError(&diags, "Failed importing images", k3dObject{Kind: "cluster", Name: data.Cluster.ValueString()}, output, err)
+			return diags
+		}
+		tflog.Info(ctx, "imported images", map[string]interface{}{"cluster": data.Cluster.ValueString(), "images": changed})
+	}
+
+	data.ImageIDs, err = imageIDsValue(ids)
+	if err != nil {
+		diags.AddError("Failed setting image IDs", fmt.Sprint(err))
+	}
+	return diags
+}
+
+// imageIDs returns the IDs of the local images, or the checksums of the
+// tarballs, by image. Images without a known ID have an empty ID.
+func (r *ImageImportResource) imageIDs(ctx context.Context, images []string) (map[string]string, error) {
+	ids := map[string]string{}
+	var references []string
+	for _, image := range images {
+		// k3d treats arguments naming an existing file as tarballs.
+		if info, err := os.Stat(image); err == nil && info.Mode().IsRegular() {
+			checksum, err := fileChecksum(image)
+			if err != nil {
+				return nil, err
+			}
+			ids[image] = checksum
+			continue
+		}
+		references = append(references, image)
+		ids[image] = ""
+	}
+
+	if len(references) == 0 {
+		return ids, nil
+	}
+	if r.docker == nil {
+		return ids, errDockerUnavailable
+	}
+	args := append([]string{"image", "inspect", "--format", "{{.Id}}"}, references...)
+	output, err := r.docker.Run(ctx, args...)
+	if errors.Is(err, errDockerUnavailable) {
+		return ids, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", strings.TrimSpace(string(output)), err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if len(lines) != len(references) {
+		return nil, fmt.Errorf("expected %d image IDs from docker, got: %s", len(references), output)
+	}
+	for i, reference := range references {
+		ids[reference] = strings.TrimSpace(lines[i])
+	}
+	return ids, nil
+}
+
+// keepImportedIDs sets the IDs of image references, which are unknown without
+// the docker CLI, to the IDs they were imported with.
+func keepImportedIDs(ids map[string]string, imported map[string]string) {
+	for image, id := range ids {
+		if id == "" {
+			ids[image] = imported[image]
+		}
+	}
+}
+
+// imageIDsEqual reports whether the image IDs of a state match ids.
+func imageIDsEqual(value types.Map, ids map[string]string) bool {
+	current, err := imageIDsValue(ids)
+	return err == nil && value.Equal(current)
+}
+
+func imageIDsValue(ids map[string]string) (types.Map, error) {
+	value, diags := types.MapValueFrom(context.Background(), types.StringType, ids)
+	if diags.HasError() {
+		return value, fmt.Errorf("%v", diags)
+	}
+	return value, nil
+}
+
+// fileChecksum returns the SHA-256 checksum of a file in the format of image
+// IDs.
+func fileChecksum(name string) (string, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("sha256:%x", hash.Sum(nil)), nil
+}
+
+// imageImportID returns the ID of images imported into the cluster.
+func imageImportID(cluster K3dClusterInfo) string {
+	return fmt.Sprintf("%s/%s", cluster.Name, cluster.InstanceID())
+}
+
+// shortChecksum returns a short SHA-256 checksum of content.
+func shortChecksum(content string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(content)))[:12]
+}
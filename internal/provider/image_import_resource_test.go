@@ -0,0 +1,302 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	fwresource "github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestImageImportResourceCreate(t *testing.T) {
+	tarball := filepath.Join(t.TempDir(), "app.tar")
+	if err := os.WriteFile(tarball, []byte("image"), 0o600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	runner := (&fakeK3dRunner{}).
+		On(testImageImportClusters, nil, "cluster", "list").
+		On("", nil, "image", "import")
+	docker := (&fakeK3dRunner{}).
+		On("sha256:abc\n", nil, "image", "inspect")
+	r := &ImageImportResource{runner: runner, docker: docker}
+
+	req := fwresource.CreateRequest{Plan: newTestPlan(t, testResourceSchema(t, &ImageImportResource{}), ImageImportResourceModel{
+		ID:       types.StringUnknown(),
+		Cluster:  types.StringValue("test"),
+		Images:   types.ListValueMust(types.StringType, []attr.Value{types.StringValue("example/app:dev"), types.StringValue(tarball)}),
+		ImageIDs: types.MapUnknown(types.StringType),
+	})}
+	resp := &fwresource.CreateResponse{State: newTestState(t, testResourceSchema(t, &ImageImportResource{}), nil)}
+	r.Create(context.Background(), req, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+	if !runner.Called("image", "import", "example/app:dev", tarball, "--cluster", "test") {
+		t.Errorf("expected image import, got %v", runner.calls)
+	}
+	// Tarballs are not inspected with docker.
+	if !docker.Called("image", "inspect", "--format", "{{.Id}}", "example/app:dev") || len(docker.calls[0]) != 5 {
+		t.Errorf("expected docker image inspect of the image reference, got %v", docker.calls)
+	}
+	data := getTestModel[ImageImportResourceModel](t, resp.State)
+	if got := data.ID.ValueString(); got != "test/"+shortChecksum("net123/2022-12-01T10:00:00Z") {
+		t.Errorf("expected ID of the cluster instance, got %s", got)
+	}
+	ids := map[string]string{}
+	data.ImageIDs.ElementsAs(context.Background(), &ids, false)
+	if got := ids["example/app:dev"]; got != "sha256:abc" {
+		t.Errorf("expected image ID sha256:abc, got %s", got)
+	}
+	// SHA-256 checksum of "image".
+	if got := ids[tarball]; got != "sha256:6105d6cc76af400325e94d588ce511be5bfdbb73b437dc51eca43917d7a43e3d" {
+		t.Errorf("expected tarball checksum, got %s", got)
+	}
+}
+
+func TestImageImportResourceCreateMissingImage(t *testing.T) {
+	docker := (&fakeK3dRunner{}).
+		On("Error: No such image: example/app:dev", errors.New("exit status 1"), "image", "inspect")
+	runner := (&fakeK3dRunner{}).
+		On(testImageImportClusters, nil, "cluster", "list")
+	r := &ImageImportResource{runner: runner, docker: docker}
+
+	req := fwresource.CreateRequest{Plan: newTestPlan(t, testResourceSchema(t, &ImageImportResource{}), ImageImportResourceModel{
+		ID:       types.StringUnknown(),
+		Cluster:  types.StringValue("test"),
+		Images:   types.ListValueMust(types.StringType, []attr.Value{types.StringValue("example/app:dev")}),
+		ImageIDs: types.MapUnknown(types.StringType),
+	})}
+	resp := &fwresource.CreateResponse{State: newTestState(t, testResourceSchema(t, &ImageImportResource{}), nil)}
+	r.Create(context.Background(), req, resp)
+
+	if !resp.Diagnostics.HasError() {
+		t.Fatal("expected error")
+	}
+	if got := resp.Diagnostics[0].Summary(); got != "Failed looking up images" {
+		t.Errorf("expected image lookup error, got %q", got)
+	}
+}
+
+func TestImageImportResourceModifyPlan(t *testing.T) {
+	images := types.ListValueMust(types.StringType, []attr.Value{types.StringValue("example/app:dev")})
+	state := &ImageImportResourceModel{
+		ID:       types.StringValue("test/123"),
+		Cluster:  types.StringValue("test"),
+		Images:   images,
+		ImageIDs: types.MapValueMust(types.StringType, map[string]attr.Value{"example/app:dev": types.StringValue("sha256:abc")}),
+	}
+
+	for _, tc := range []struct {
+		name    string
+		id      string
+		changed bool
+	}{
+		{name: "unchanged", id: "sha256:abc", changed: false},
+		{name: "rebuilt", id: "sha256:def", changed: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			docker := (&fakeK3dRunner{}).
+				On(tc.id+"\n", nil, "image", "inspect")
+			r := &ImageImportResource{docker: docker}
+
+			plan := newTestPlan(t, testResourceSchema(t, &ImageImportResource{}), *state)
+			req := fwresource.ModifyPlanRequest{State: newTestState(t, testResourceSchema(t, &ImageImportResource{}), state), Plan: plan}
+			resp := &fwresource.ModifyPlanResponse{Plan: plan}
+			r.ModifyPlan(context.Background(), req, resp)
+
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected error: %v", resp.Diagnostics)
+			}
+			var data ImageImportResourceModel
+			resp.Plan.Get(context.Background(), &data)
+			if got := data.ImageIDs.IsUnknown(); got != tc.changed {
+				t.Errorf("expected image_ids to be unknown %t, got %t", tc.changed, got)
+			}
+		})
+	}
+}
+
+func TestImageImportResourceUpdate(t *testing.T) {
+	runner := (&fakeK3dRunner{}).
+		On("", nil, "image", "import")
+	docker := (&fakeK3dRunner{}).
+		On("sha256:abc\nsha256:new\n", nil, "image", "inspect")
+	r := &ImageImportResource{runner: runner, docker: docker}
+
+	images := types.ListValueMust(types.StringType, []attr.Value{types.StringValue("example/app:dev"), types.StringValue("example/worker:dev")})
+	state := newTestState(t, testResourceSchema(t, &ImageImportResource{}), &ImageImportResourceModel{
+		ID:       types.StringValue("test/123"),
+		Cluster:  types.StringValue("test"),
+		Images:   images,
+		ImageIDs: types.MapValueMust(types.StringType, map[string]attr.Value{"example/app:dev": types.StringValue("sha256:abc"), "example/worker:dev": types.StringValue("sha256:old")}),
+	})
+	req := fwresource.UpdateRequest{State: state, Plan: newTestPlan(t, testResourceSchema(t, &ImageImportResource{}), ImageImportResourceModel{
+		ID:       types.StringValue("test/123"),
+		Cluster:  types.StringValue("test"),
+		Images:   images,
+		ImageIDs: types.MapUnknown(types.StringType),
+	})}
+	resp := &fwresource.UpdateResponse{State: state}
+	r.Update(context.Background(), req, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+	// Only the changed image is imported again.
+	if len(runner.calls) != 1 || !runner.Called("image", "import", "example/worker:dev", "--cluster", "test") {
+		t.Errorf("expected import of the changed image only, got %v", runner.calls)
+	}
+}
+
+func TestImageImportResourceCreateWithoutDocker(t *testing.T) {
+	runner := (&fakeK3dRunner{}).
+		On(testImageImportClusters, nil, "cluster", "list").
+		On("", nil, "image", "import")
+	r := &ImageImportResource{runner: runner}
+
+	req := fwresource.CreateRequest{Plan: newTestPlan(t, testResourceSchema(t, &ImageImportResource{}), ImageImportResourceModel{
+		ID:       types.StringUnknown(),
+		Cluster:  types.StringValue("test"),
+		Images:   types.ListValueMust(types.StringType, []attr.Value{types.StringValue("example/app:dev")}),
+		ImageIDs: types.MapUnknown(types.StringType),
+	})}
+	resp := &fwresource.CreateResponse{State: newTestState(t, testResourceSchema(t, &ImageImportResource{}), nil)}
+	r.Create(context.Background(), req, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+	if resp.Diagnostics.WarningsCount() != 1 || resp.Diagnostics[0].Summary() != "Image changes are not detected" {
+		t.Errorf("expected warning about image changes, got %v", resp.Diagnostics)
+	}
+	if !runner.Called("image", "import", "example/app:dev", "--cluster", "test") {
+		t.Errorf("expected image import, got %v", runner.calls)
+	}
+}
+
+func TestImageImportResourceModifyPlanWithoutDocker(t *testing.T) {
+	state := &ImageImportResourceModel{
+		ID:       types.StringValue("test/123"),
+		Cluster:  types.StringValue("test"),
+		Images:   types.ListValueMust(types.StringType, []attr.Value{types.StringValue("example/app:dev")}),
+		ImageIDs: types.MapValueMust(types.StringType, map[string]attr.Value{"example/app:dev": types.StringValue("")}),
+	}
+	docker := (&fakeK3dRunner{}).
+		On("", fmt.Errorf("%w: executable file not found in $PATH", errDockerUnavailable), "image", "inspect")
+	r := &ImageImportResource{docker: docker}
+
+	plan := newTestPlan(t, testResourceSchema(t, &ImageImportResource{}), *state)
+	req := fwresource.ModifyPlanRequest{State: newTestState(t, testResourceSchema(t, &ImageImportResource{}), state), Plan: plan}
+	resp := &fwresource.ModifyPlanResponse{Plan: plan}
+	r.ModifyPlan(context.Background(), req, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+	var data ImageImportResourceModel
+	resp.Plan.Get(context.Background(), &data)
+	if data.ImageIDs.IsUnknown() {
+		t.Error("expected image_ids to be kept without the docker CLI")
+	}
+}
+
+func TestImageImportResourceUpdateWithoutDocker(t *testing.T) {
+	runner := (&fakeK3dRunner{}).
+		On("", nil, "image", "import")
+	r := &ImageImportResource{runner: runner}
+
+	state := newTestState(t, testResourceSchema(t, &ImageImportResource{}), &ImageImportResourceModel{
+		ID:       types.StringValue("test/123"),
+		Cluster:  types.StringValue("test"),
+		Images:   types.ListValueMust(types.StringType, []attr.Value{types.StringValue("example/app:dev")}),
+		ImageIDs: types.MapValueMust(types.StringType, map[string]attr.Value{"example/app:dev": types.StringValue("sha256:abc")}),
+	})
+	req := fwresource.UpdateRequest{State: state, Plan: newTestPlan(t, testResourceSchema(t, &ImageImportResource{}), ImageImportResourceModel{
+		ID:       types.StringValue("test/123"),
+		Cluster:  types.StringValue("test"),
+		Images:   types.ListValueMust(types.StringType, []attr.Value{types.StringValue("example/app:dev"), types.StringValue("example/worker:dev")}),
+		ImageIDs: types.MapUnknown(types.StringType),
+	})}
+	resp := &fwresource.UpdateResponse{State: state}
+	r.Update(context.Background(), req, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+	// Only the added image is imported, the other one keeps its ID.
+	if len(runner.calls) != 1 || !runner.Called("image", "import", "example/worker:dev", "--cluster", "test") {
+		t.Errorf("expected import of the added image only, got %v", runner.calls)
+	}
+	data := getTestModel[ImageImportResourceModel](t, resp.State)
+	ids := map[string]string{}
+	data.ImageIDs.ElementsAs(context.Background(), &ids, false)
+	if got := ids["example/app:dev"]; got != "sha256:abc" {
+		t.Errorf("expected image ID sha256:abc to be kept, got %q", got)
+	}
+}
+
+func TestImageImportResourceReadMissingCluster(t *testing.T) {
+	runner := (&fakeK3dRunner{}).
+		On("[]", nil, "cluster", "list")
+	r := &ImageImportResource{runner: runner}
+
+	state := newTestState(t, testResourceSchema(t, &ImageImportResource{}), &ImageImportResourceModel{
+		ID:       types.StringValue("test/123"),
+		Cluster:  types.StringValue("test"),
+		Images:   types.ListValueMust(types.StringType, []attr.Value{types.StringValue("example/app:dev")}),
+		ImageIDs: types.MapValueMust(types.StringType, map[string]attr.Value{"example/app:dev": types.StringValue("sha256:abc")}),
+	})
+	resp := &fwresource.ReadResponse{State: state}
+	r.Read(context.Background(), fwresource.ReadRequest{State: state}, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+	if !resp.State.Raw.IsNull() {
+		t.Error("expected resource to be removed from state")
+	}
+}
+
+func TestImageImportResourceReadRecreatedCluster(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		id      string
+		removed bool
+	}{
+		{name: "same cluster", id: "test/" + shortChecksum("net123/2022-12-01T10:00:00Z"), removed: false},
+		{name: "recreated cluster", id: "test/" + shortChecksum("net000/2022-11-01T10:00:00Z"), removed: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			runner := (&fakeK3dRunner{}).
+				On(testImageImportClusters, nil, "cluster", "list")
+			r := &ImageImportResource{runner: runner}
+
+			state := newTestState(t, testResourceSchema(t, &ImageImportResource{}), &ImageImportResourceModel{
+				ID:       types.StringValue(tc.id),
+				Cluster:  types.StringValue("test"),
+				Images:   types.ListValueMust(types.StringType, []attr.Value{types.StringValue("example/app:dev")}),
+				ImageIDs: types.MapValueMust(types.StringType, map[string]attr.Value{"example/app:dev": types.StringValue("sha256:abc")}),
+			})
+			resp := &fwresource.ReadResponse{State: state}
+			r.Read(context.Background(), fwresource.ReadRequest{State: state}, resp)
+
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected error: %v", resp.Diagnostics)
+			}
+			if got := resp.State.Raw.IsNull(); got != tc.removed {
+				t.Errorf("expected resource to be removed %t, got %t", tc.removed, got)
+			}
+		})
+	}
+}
+
+// testImageImportClusters is the output of `k3d cluster list --output json`
+// with a single cluster named test.
+const testImageImportClusters = `[{"name":"test","network":{"name":"k3d-test","id":"net123"},"serversCount":1,"serversRunning":1,` +
+	`"nodes":[{"name":"k3d-test-server-0","role":"server","created":"2022-12-01T10:00:00Z"}]}]`
@@ -0,0 +1,378 @@
+package provider
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// K3dConfigNodeCounts holds the amount of server and agent nodes a k3d config
+// defines.
+type K3dConfigNodeCounts struct {
+	Servers int
+	Agents  int
+}
+
+// parseK3dConfig parses k3d config content into a generic map.
+func parseK3dConfig(content string) (map[string]interface{}, error) {
+	config := map[string]interface{}{}
+	if err := yaml.Unmarshal([]byte(content), &config); err != nil {
+		return nil, fmt.Errorf("failed parsing k3d config: %w", err)
+	}
+	return config, nil
+}
+
+// k3dConfigNodeCounts returns the node counts of a parsed k3d config, using
+// the k3d defaults of 1 server and 0 agents.
+func k3dConfigNodeCounts(config map[string]interface{}) K3dConfigNodeCounts {
+	counts := K3dConfigNodeCounts{Servers: 1, Agents: 0}
+	if servers, ok := config["servers"].(int); ok {
+		counts.Servers = servers
+	}
+	if agents, ok := config["agents"].(int); ok {
+		counts.Agents = agents
+	}
+	return counts
+}
+
+// k3dConfigScalable reports whether the k3d config a of a running cluster can
+// be changed to b in place. Options k3d chooses are only compared when b sets
+// them. Agents can always be added and removed. Servers
+// can only be added to clusters which already have more than one server,
+// because k3d starts clusters with a single server with SQLite instead of
+// embedded etcd, which other servers cannot join. Removing servers leaves
+// their etcd members registered and can cost the cluster its quorum.
+func k3dConfigScalable(a string, b string) (bool, error) {
+	configA, err := parseK3dConfig(a)
+	if err != nil {
+		return false, err
+	}
+	configB, err := parseK3dConfig(b)
+	if err != nil {
+		return false, err
+	}
+
+	serversA := k3dConfigNodeCounts(configA).Servers
+	serversB := k3dConfigNodeCounts(configB).Servers
+	if serversB != serversA && (serversA < 2 || serversB < serversA) {
+		return false, nil
+	}
+
+	delete(configA, "servers")
+	delete(configA, "agents")
+	delete(configB, "servers")
+	delete(configB, "agents")
+	ignoreUnsetK3dOptions(configA, configB)
+	return reflect.DeepEqual(configA, configB), nil
+}
+
+// k3dChosenOptions are the k3d config options k3d chooses a value for when
+// they are not set: the k3s image of the k3d release, and the address and a
+// random host port of the Kubernetes API.
+var k3dChosenOptions = []string{"image", "kubeAPI"}
+
+// ignoreUnsetK3dOptions removes the options k3d chooses from the parsed config
+// a when the parsed config b does not set them, including the fields of
+// kubeAPI b does not set. A config leaving them unset accepts whatever k3d
+// chose, such as the values of a config reconstructed on import.
+func ignoreUnsetK3dOptions(a map[string]interface{}, b map[string]interface{}) {
+	for _, key := range k3dChosenOptions {
+		valueB, ok := b[key]
+		if !ok {
+			delete(a, key)
+			continue
+		}
+		fieldsA, okA := a[key].(map[string]interface{})
+		fieldsB, okB := valueB.(map[string]interface{})
+		if !okA || !okB {
+			continue
+		}
+		for field := range fieldsA {
+			if _, ok := fieldsB[field]; !ok {
+				delete(fieldsA, field)
+			}
+		}
+	}
+}
+
+// K3dSimpleConfig is the subset of the k3d.io/v1alpha4 Simple config the
+// provider renders.
+type K3dSimpleConfig struct {
+	APIVersion string                     `yaml:"apiVersion"`
+	Kind       string                     `yaml:"kind"`
+	Servers    int                        `yaml:"servers"`
+	Agents     int                        `yaml:"agents"`
+	KubeAPI    *K3dSimpleConfigKubeAPI    `yaml:"kubeAPI,omitempty"`
+	Image      string                     `yaml:"image,omitempty"`
+	Ports      []K3dSimpleConfigPort      `yaml:"ports,omitempty"`
+	Volumes    []K3dSimpleConfigVolume    `yaml:"volumes,omitempty"`
+	Env        []K3dSimpleConfigEnv       `yaml:"env,omitempty"`
+	Registries *K3dSimpleConfigRegistries `yaml:"registries,omitempty"`
+	Options    *K3dSimpleConfigOptions    `yaml:"options,omitempty"`
+}
+
+type K3dSimpleConfigKubeAPI struct {
+	Host     string `yaml:"host,omitempty"`
+	HostIP   string `yaml:"hostIP,omitempty"`
+	HostPort string `yaml:"hostPort,omitempty"`
+}
+
+type K3dSimpleConfigPort struct {
+	Port        string   `yaml:"port"`
+	NodeFilters []string `yaml:"nodeFilters"`
+}
+
+type K3dSimpleConfigVolume struct {
+	Volume      string   `yaml:"volume"`
+	NodeFilters []string `yaml:"nodeFilters,omitempty"`
+}
+
+type K3dSimpleConfigEnv struct {
+	EnvVar      string   `yaml:"envVar"`
+	NodeFilters []string `yaml:"nodeFilters,omitempty"`
+}
+
+type K3dSimpleConfigRegistries struct {
+	Create *K3dSimpleConfigRegistryCreate `yaml:"create,omitempty"`
+	Use    []string                       `yaml:"use,omitempty"`
+	Config string                         `yaml:"config,omitempty"`
+}
+
+type K3dSimpleConfigRegistryCreate struct {
+	Name     string                        `yaml:"name,omitempty"`
+	Host     string                        `yaml:"host,omitempty"`
+	HostPort string                        `yaml:"hostPort,omitempty"`
+	Proxy    *K3dSimpleConfigRegistryProxy `yaml:"proxy,omitempty"`
+}
+
+type K3dSimpleConfigRegistryProxy struct {
+	RemoteURL string `yaml:"remoteURL"`
+}
+
+type K3dSimpleConfigOptions struct {
+	K3d *K3dSimpleConfigK3dOptions `yaml:"k3d,omitempty"`
+	K3s *K3dSimpleConfigK3sOptions `yaml:"k3s,omitempty"`
+}
+
+// K3dSimpleConfigK3dOptions uses pointers so options explicitly set to false
+// are rendered, since some of them default to true in k3d.
+type K3dSimpleConfigK3dOptions struct {
+	Wait                *bool  `yaml:"wait,omitempty"`
+	Timeout             string `yaml:"timeout,omitempty"`
+	DisableLoadbalancer *bool  `yaml:"disableLoadbalancer,omitempty"`
+	DisableImageVolume  *bool  `yaml:"disableImageVolume,omitempty"`
+	DisableRollback     *bool  `yaml:"disableRollback,omitempty"`
+}
+
+type K3dSimpleConfigK3sOptions struct {
+	ExtraArgs  []K3dSimpleConfigArg   `yaml:"extraArgs,omitempty"`
+	NodeLabels []K3dSimpleConfigLabel `yaml:"nodeLabels,omitempty"`
+}
+
+type K3dSimpleConfigArg struct {
+	Arg         string   `yaml:"arg"`
+	NodeFilters []string `yaml:"nodeFilters,omitempty"`
+}
+
+type K3dSimpleConfigLabel struct {
+	Label       string   `yaml:"label"`
+	NodeFilters []string `yaml:"nodeFilters,omitempty"`
+}
+
+// k3dConfigFromCluster reconstructs a representative config of a running
+// cluster from its nodes. Options k3d does not expose on the nodes, such as
+// registries, are not part of the result.
+func k3dConfigFromCluster(cluster K3dClusterInfo) K3dSimpleConfig {
+	config := K3dSimpleConfig{
+		APIVersion: "k3d.io/v1alpha4",
+		Kind:       "Simple",
+		Servers:    len(cluster.NodesWithRole("server")),
+		Agents:     len(cluster.NodesWithRole("agent")),
+		Image:      cluster.Image(),
+	}
+
+	for _, node := range cluster.NodesWithRole("loadbalancer") {
+		containerPorts := make([]string, 0, len(node.PortMappings))
+		for containerPort := range node.PortMappings {
+			containerPorts = append(containerPorts, containerPort)
+		}
+		sort.Strings(containerPorts)
+
+		for _, containerPort := range containerPorts {
+			for _, binding := range node.PortMappings[containerPort] {
+				// The Kubernetes API is exposed through the load balancer on port 6443.
+				if containerPort == "6443/tcp" {
+					config.KubeAPI = &K3dSimpleConfigKubeAPI{HostIP: binding.HostIP, HostPort: binding.HostPort}
+					continue
+				}
+				port := binding.HostPort + ":" + strings.TrimSuffix(containerPort, "/tcp")
+				if binding.HostIP != "" && binding.HostIP != "0.0.0.0" {
+					port = binding.HostIP + ":" + port
+				}
+				config.Ports = append(config.Ports, K3dSimpleConfigPort{
+					Port:        port,
+					NodeFilters: []string{"loadbalancer"},
+				})
+			}
+		}
+	}
+	return config
+}
+
+// reconcileK3dConfig returns k3d config content with the node counts, image
+// and load balancer ports replaced by the ones observed on the cluster, and
+// whether they differed. Other options are kept as configured, since k3d does
+// not expose them on the nodes. Images of release channels such as `+stable`
+// are resolved by k3d and can not be compared.
+func reconcileK3dConfig(content string, cluster K3dClusterInfo) (string, bool, error) {
+	config, err := parseK3dConfig(content)
+	if err != nil {
+		return "", false, err
+	}
+	var document yaml.Node
+	if err := yaml.Unmarshal([]byte(content), &document); err != nil {
+		return "", false, fmt.Errorf("failed parsing k3d config: %w", err)
+	}
+	if len(document.Content) == 0 || document.Content[0].Kind != yaml.MappingNode {
+		return content, false, nil
+	}
+	mapping := document.Content[0]
+
+	drifted := false
+	counts := k3dConfigNodeCounts(config)
+	if servers := cluster.NodeCount("server"); counts.Servers != servers {
+		setK3dConfigValue(mapping, "servers", strconv.Itoa(servers), "!!int")
+		drifted = true
+	}
+	if agents := cluster.NodeCount("agent"); counts.Agents != agents {
+		setK3dConfigValue(mapping, "agents", strconv.Itoa(agents), "!!int")
+		drifted = true
+	}
+	image, _ := config["image"].(string)
+	observedImage := cluster.Image()
+	if image != "" && !strings.HasPrefix(image, "+") && observedImage != "" && image != observedImage {
+		setK3dConfigValue(mapping, "image", observedImage, "!!str")
+		drifted = true
+	}
+	if ports, ok := k3dConfigPortDrift(config, cluster); ok {
+		var node yaml.Node
+		if err := node.Encode(ports); err != nil {
+			return "", false, fmt.Errorf("failed rendering k3d config ports: %w", err)
+		}
+		setK3dConfigNode(mapping, "ports", &node)
+		drifted = true
+	}
+	if !drifted {
+		return content, false, nil
+	}
+
+	reconciled, err := yaml.Marshal(&document)
+	if err != nil {
+		return "", false, fmt.Errorf("failed rendering k3d config: %w", err)
+	}
+	return string(reconciled), true, nil
+}
+
+// k3dConfigPortDrift returns the load balancer ports observed on the cluster
+// when they differ from the ports of a parsed k3d config. Ports are only
+// compared when all configured ports are published on the load balancer with
+// a fixed host port, since k3d does not record the node filters of other
+// ports, and random host ports and port ranges are resolved when the cluster
+// is created.
+func k3dConfigPortDrift(config map[string]interface{}, cluster K3dClusterInfo) ([]K3dSimpleConfigPort, bool) {
+	if len(cluster.NodesWithRole("loadbalancer")) == 0 {
+		return nil, false
+	}
+
+	var configured []string
+	entries, _ := config["ports"].([]interface{})
+	for _, entry := range entries {
+		port, _ := entry.(map[string]interface{})
+		spec, _ := port["port"].(string)
+		filters, _ := port["nodeFilters"].([]interface{})
+		if len(filters) != 1 || filters[0] != "loadbalancer" {
+			return nil, false
+		}
+		key, ok := k3dPortKey(spec)
+		if !ok {
+			return nil, false
+		}
+		configured = append(configured, key)
+	}
+
+	observedPorts := k3dConfigFromCluster(cluster).Ports
+	var observed []string
+	for _, port := range observedPorts {
+		key, ok := k3dPortKey(port.Port)
+		if !ok {
+			return nil, false
+		}
+		observed = append(observed, key)
+	}
+
+	sort.Strings(configured)
+	sort.Strings(observed)
+	if reflect.DeepEqual(configured, observed) || (len(configured) == 0 && len(observed) == 0) {
+		return nil, false
+	}
+	if observedPorts == nil {
+		observedPorts = []K3dSimpleConfigPort{}
+	}
+	return observedPorts, true
+}
+
+// k3dPortKey normalizes a k3d port mapping such as `8080:80` or
+// `127.0.0.1:8080:80/udp` to `hostIP:hostPort:containerPort/protocol`. It
+// reports false for mappings without a host port and for port ranges.
+func k3dPortKey(spec string) (string, bool) {
+	protocol := "tcp"
+	if i := strings.LastIndex(spec, "/"); i >= 0 {
+		spec, protocol = spec[:i], spec[i+1:]
+	}
+	if strings.Contains(spec, "-") {
+		return "", false
+	}
+	hostIP := "0.0.0.0"
+	parts := strings.Split(spec, ":")
+	if len(parts) == 3 {
+		hostIP, parts = parts[0], parts[1:]
+	}
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", false
+	}
+	return fmt.Sprintf("%s:%s:%s/%s", hostIP, parts[0], parts[1], protocol), true
+}
+
+// setK3dConfigNode sets the value of key in a YAML mapping to node, adding the
+// key when it is missing.
+func setK3dConfigNode(mapping *yaml.Node, key string, node *yaml.Node) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			mapping.Content[i+1] = node
+			return
+		}
+	}
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, node)
+}
+
+// setK3dConfigValue sets the scalar value of key in a YAML mapping, adding the
+// key when it is missing.
+func setK3dConfigValue(mapping *yaml.Node, key string, value string, tag string) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			mapping.Content[i+1].Kind = yaml.ScalarNode
+			mapping.Content[i+1].Tag = tag
+			mapping.Content[i+1].Value = value
+			mapping.Content[i+1].Style = 0
+			return
+		}
+	}
+	mapping.Content = append(mapping.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key},
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: value},
+	)
+}
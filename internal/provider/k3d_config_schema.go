@@ -0,0 +1,207 @@
+package provider
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// k3dConfigSchemaContent is the JSON schema k3d validates k3d.io/v1alpha4
+// Simple configs against.
+//
+//go:embed k3d_config_schema.json
+var k3dConfigSchemaContent []byte
+
+// k3dConfigSchema is the subset of JSON schema used by the k3d config schema.
+type k3dConfigSchema struct {
+	Ref                  string                      `json:"$ref"`
+	Type                 string                      `json:"type"`
+	Enum                 []string                    `json:"enum"`
+	Minimum              *float64                    `json:"minimum"`
+	Required             []string                    `json:"required"`
+	Properties           map[string]*k3dConfigSchema `json:"properties"`
+	AdditionalProperties *bool                       `json:"additionalProperties"`
+	Items                *k3dConfigSchema            `json:"items"`
+	Definitions          map[string]*k3dConfigSchema `json:"definitions"`
+}
+
+// K3dConfigError describes a violation of the k3d config schema.
+type K3dConfigError struct {
+	// Line is the line of the offending YAML node, or 0 when unknown.
+	Line int
+	// Field is the path of the offending field, such as `ports[0].port`.
+	Field   string
+	Message string
+}
+
+func (e K3dConfigError) Error() string {
+	message := e.Message
+	if e.Field != "" {
+		message = fmt.Sprintf("%s: %s", e.Field, message)
+	}
+	if e.Line > 0 {
+		message = fmt.Sprintf("line %d: %s", e.Line, message)
+	}
+	return message
+}
+
+// validateK3dConfig validates k3d config content against the embedded k3d
+// config schema, so invalid configs are reported without running k3d.
+func validateK3dConfig(content string) []K3dConfigError {
+	var schema k3dConfigSchema
+	if err := json.Unmarshal(k3dConfigSchemaContent, &schema); err != nil {
+		panic(fmt.Sprintf("invalid embedded k3d config schema: %s", err))
+	}
+
+	var document yaml.Node
+	if err := yaml.Unmarshal([]byte(content), &document); err != nil {
+		return []K3dConfigError{{Message: err.Error()}}
+	}
+
+	// Empty content is validated as an empty mapping, reporting missing fields.
+	node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: 1}
+	if len(document.Content) > 0 {
+		node = document.Content[0]
+	}
+	return schema.validate(&schema, node, "")
+}
+
+func (s *k3dConfigSchema) validate(root *k3dConfigSchema, node *yaml.Node, field string) []K3dConfigError {
+	if s.Ref != "" {
+		definition, ok := root.Definitions[strings.TrimPrefix(s.Ref, "#/definitions/")]
+		if !ok {
+			panic(fmt.Sprintf("unknown k3d config schema reference %s", s.Ref))
+		}
+		return definition.validate(root, node, field)
+	}
+
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	// k3d ignores empty values, such as a `ports:` key without items.
+	if node.Tag == "!!null" {
+		return nil
+	}
+
+	if s.Type != "" && !k3dConfigNodeHasType(node, s.Type) {
+		return []K3dConfigError{{
+			Line:    node.Line,
+			Field:   field,
+			Message: fmt.Sprintf("expected %s, got %s", s.Type, k3dConfigNodeType(node)),
+		}}
+	}
+
+	errs := []K3dConfigError{}
+	if len(s.Enum) > 0 && !containsString(s.Enum, node.Value) {
+		errs = append(errs, K3dConfigError{
+			Line:    node.Line,
+			Field:   field,
+			Message: fmt.Sprintf("unsupported value %q, must be one of %q", node.Value, s.Enum),
+		})
+	}
+	if s.Minimum != nil {
+		if value, err := strconv.ParseFloat(node.Value, 64); err == nil && value < *s.Minimum {
+			errs = append(errs, K3dConfigError{
+				Line:    node.Line,
+				Field:   field,
+				Message: fmt.Sprintf("must be at least %v", *s.Minimum),
+			})
+		}
+	}
+
+	switch node.Kind {
+	case yaml.MappingNode:
+		present := map[string]bool{}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			present[key.Value] = true
+
+			property, ok := s.Properties[key.Value]
+			if !ok {
+				if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+					errs = append(errs, K3dConfigError{
+						Line:    key.Line,
+						Field:   joinK3dConfigField(field, key.Value),
+						Message: "unknown field",
+					})
+				}
+				continue
+			}
+			errs = append(errs, property.validate(root, value, joinK3dConfigField(field, key.Value))...)
+		}
+		for _, name := range s.Required {
+			if !present[name] {
+				errs = append(errs, K3dConfigError{
+					Line:    node.Line,
+					Field:   joinK3dConfigField(field, name),
+					Message: "missing required field",
+				})
+			}
+		}
+	case yaml.SequenceNode:
+		if s.Items != nil {
+			for i, item := range node.Content {
+				errs = append(errs, s.Items.validate(root, item, fmt.Sprintf("%s[%d]", field, i))...)
+			}
+		}
+	}
+	return errs
+}
+
+// k3dConfigNodeHasType reports whether a YAML node matches a JSON schema type.
+func k3dConfigNodeHasType(node *yaml.Node, schemaType string) bool {
+	switch schemaType {
+	case "object":
+		return node.Kind == yaml.MappingNode
+	case "array":
+		return node.Kind == yaml.SequenceNode
+	case "string":
+		return node.Kind == yaml.ScalarNode && node.Tag == "!!str"
+	case "number":
+		return node.Kind == yaml.ScalarNode && (node.Tag == "!!int" || node.Tag == "!!float")
+	case "integer":
+		return node.Kind == yaml.ScalarNode && node.Tag == "!!int"
+	case "boolean":
+		return node.Kind == yaml.ScalarNode && node.Tag == "!!bool"
+	}
+	return true
+}
+
+// k3dConfigNodeType describes the type of a YAML node in JSON schema terms.
+func k3dConfigNodeType(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "object"
+	case yaml.SequenceNode:
+		return "array"
+	}
+	switch node.Tag {
+	case "!!int", "!!float":
+		return "number"
+	case "!!bool":
+		return "boolean"
+	case "!!str":
+		return "string"
+	}
+	return strings.TrimPrefix(node.Tag, "!!")
+}
+
+func joinK3dConfigField(parent string, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
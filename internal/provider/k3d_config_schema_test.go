@@ -0,0 +1,95 @@
+package provider
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestValidateK3dConfig(t *testing.T) {
+	cases := []struct {
+		name   string
+		config string
+		want   []string
+	}{
+		{"minimal", "apiVersion: k3d.io/v1alpha4\nkind: Simple\n", nil},
+		{"full", `apiVersion: k3d.io/v1alpha4
+kind: Simple
+servers: 1
+agents: 2
+kubeAPI:
+  hostIP: 0.0.0.0
+  hostPort: "6445"
+ports:
+  - port: 3080:80
+    nodeFilters:
+      - loadbalancer
+registries:
+  create:
+    name: dev
+    hostPort: "5000"
+options:
+  k3d:
+    wait: true
+  k3s:
+    extraArgs:
+      - arg: --disable=traefik
+        nodeFilters:
+          - server:*
+    nodeLabels:
+      - label: foo=bar
+`, nil},
+		{"empty value", "apiVersion: k3d.io/v1alpha4\nkind: Simple\nports:\n", nil},
+		{"empty", "", []string{"line 1: apiVersion: missing required field", "line 1: kind: missing required field"}},
+		{"api version", "apiVersion: k3d.io/v1alpha2\nkind: Simple\n", []string{`line 1: apiVersion: unsupported value "k3d.io/v1alpha2", must be one of ["k3d.io/v1alpha4"]`}},
+		{"unknown field", "apiVersion: k3d.io/v1alpha4\nkind: Simple\noptions:\n  k3d:\n    wiat: true\n", []string{"line 5: options.k3d.wiat: unknown field"}},
+		{"wrong type", "apiVersion: k3d.io/v1alpha4\nkind: Simple\nagents: two\n", []string{"line 3: agents: expected number, got string"}},
+		{"minimum", "apiVersion: k3d.io/v1alpha4\nkind: Simple\nservers: 0\n", []string{"line 3: servers: must be at least 1"}},
+		{"list item", "apiVersion: k3d.io/v1alpha4\nkind: Simple\nports:\n  - port: 8080\n  - nodeFilters: [loadbalancer]\n", []string{
+			"line 4: ports[0].port: expected string, got number",
+			"line 5: ports[1].port: missing required field",
+		}},
+		{"definition", "apiVersion: k3d.io/v1alpha4\nkind: Simple\nvolumes:\n  - volume: /tmp:/tmp\n    nodeFilters: loadbalancer\n", []string{"line 5: volumes[0].nodeFilters: expected array, got string"}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			errs := validateK3dConfig(c.config)
+			if len(errs) != len(c.want) {
+				t.Fatalf("expected %d errors, got %v", len(c.want), errs)
+			}
+			for i, err := range errs {
+				if err.Error() != c.want[i] {
+					t.Errorf("expected error %q, got %q", c.want[i], err.Error())
+				}
+			}
+		})
+	}
+}
+
+func TestValidateK3dConfigInvalidYAML(t *testing.T) {
+	errs := validateK3dConfig("apiVersion: k3d.io/v1alpha4\nkind: [\n")
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error, got %v", errs)
+	}
+}
+
+func TestValidateK3dConfigRendered(t *testing.T) {
+	content, err := renderK3dConfig(ClusterConfigModel{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if errs := validateK3dConfig(content); len(errs) != 0 {
+		t.Errorf("expected rendered config to be valid, got %v", errs)
+	}
+
+	imported, err := yaml.Marshal(k3dConfigFromCluster(K3dClusterInfo{
+		Name:  "test",
+		Nodes: []K3dNodeInfo{{Name: "k3d-test-server-0", Role: "server", Image: "rancher/k3s:v1.24.4-k3s1"}},
+	}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if errs := validateK3dConfig(string(imported)); len(errs) != 0 {
+		t.Errorf("expected imported config to be valid, got %v", errs)
+	}
+}
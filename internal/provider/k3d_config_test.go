@@ -0,0 +1,196 @@
+package provider
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestK3dConfigNodeCounts(t *testing.T) {
+	config, err := parseK3dConfig("apiVersion: k3d.io/v1alpha4\nkind: Simple\nagents: 2\n")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	counts := k3dConfigNodeCounts(config)
+	if counts.Servers != 1 || counts.Agents != 2 {
+		t.Errorf("expected 1 server and 2 agents, got %+v", counts)
+	}
+}
+
+func TestK3dConfigScalable(t *testing.T) {
+	base := "apiVersion: k3d.io/v1alpha4\nkind: Simple\nservers: 1\nagents: 1\n"
+	cases := []struct {
+		name   string
+		config string
+		want   bool
+	}{
+		{"agents changed", "apiVersion: k3d.io/v1alpha4\nkind: Simple\nservers: 1\nagents: 3\n", true},
+		{"servers added to single server", "apiVersion: k3d.io/v1alpha4\nkind: Simple\nservers: 3\nagents: 1\n", false},
+		{"formatting changed", "# comment\napiVersion: k3d.io/v1alpha4\nkind:   Simple\nservers: 1\nagents: 1\n", true},
+		{"image changed", base + "image: rancher/k3s:v1.25.4-k3s1\n", false},
+		{"image unset", base, true},
+		{"kubeAPI host port unset", base + "kubeAPI:\n  hostIP: 0.0.0.0\n", true},
+		{"kubeAPI host port changed", base + "kubeAPI:\n  hostIP: 0.0.0.0\n  hostPort: \"6445\"\n", false},
+	}
+	prior := base + "image: rancher/k3s:v1.24.4-k3s1\nkubeAPI:\n  hostIP: 0.0.0.0\n  hostPort: \"40123\"\n"
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := k3dConfigScalable(prior, c.config)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != c.want {
+				t.Errorf("expected %t, got %t", c.want, got)
+			}
+		})
+	}
+}
+
+func TestK3dConfigScalableServers(t *testing.T) {
+	base := "apiVersion: k3d.io/v1alpha4\nkind: Simple\nservers: 3\n"
+	for servers, want := range map[string]bool{"3": true, "5": true, "2": false, "1": false} {
+		got, err := k3dConfigScalable(base, "apiVersion: k3d.io/v1alpha4\nkind: Simple\nservers: "+servers+"\n")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != want {
+			t.Errorf("expected %t scaling 3 servers to %s, got %t", want, servers, got)
+		}
+	}
+}
+
+func TestK3dConfigScalableInvalid(t *testing.T) {
+	if _, err := k3dConfigScalable("kind: Simple\n", "kind: [\n"); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestK3dConfigFromCluster(t *testing.T) {
+	cluster := K3dClusterInfo{
+		Name: "test",
+		Nodes: []K3dNodeInfo{
+			{Name: "k3d-test-server-0", Role: "server", Image: "rancher/k3s:v1.24.4-k3s1"},
+			{Name: "k3d-test-agent-0", Role: "agent", Image: "rancher/k3s:v1.24.4-k3s1"},
+			{Name: "k3d-test-agent-1", Role: "agent", Image: "rancher/k3s:v1.24.4-k3s1"},
+			{Name: "k3d-test-serverlb", Role: "loadbalancer", PortMappings: map[string][]K3dPortBinding{
+				"6443/tcp": {{HostIP: "0.0.0.0", HostPort: "40123"}},
+				"80/tcp":   {{HostIP: "0.0.0.0", HostPort: "3080"}},
+				"53/udp":   {{HostIP: "0.0.0.0", HostPort: "3053"}},
+			}},
+		},
+	}
+
+	content, err := yaml.Marshal(k3dConfigFromCluster(cluster))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `apiVersion: k3d.io/v1alpha4
+kind: Simple
+servers: 1
+agents: 2
+kubeAPI:
+    hostIP: 0.0.0.0
+    hostPort: "40123"
+image: rancher/k3s:v1.24.4-k3s1
+ports:
+    - port: 3053:53/udp
+      nodeFilters:
+        - loadbalancer
+    - port: 3080:80
+      nodeFilters:
+        - loadbalancer
+`
+	if string(content) != want {
+		t.Errorf("expected config:\n%s\ngot:\n%s", want, content)
+	}
+}
+
+func TestReconcileK3dConfigPorts(t *testing.T) {
+	cluster := K3dClusterInfo{
+		Name:         "test",
+		ServersCount: 1,
+		Nodes: []K3dNodeInfo{
+			{Name: "k3d-test-server-0", Role: "server"},
+			{Name: "k3d-test-serverlb", Role: "loadbalancer", PortMappings: map[string][]K3dPortBinding{
+				"6443/tcp": {{HostIP: "0.0.0.0", HostPort: "40123"}},
+				"80/tcp":   {{HostIP: "0.0.0.0", HostPort: "3080"}},
+				"443/tcp":  {{HostIP: "127.0.0.1", HostPort: "3443"}},
+			}},
+		},
+	}
+	base := "apiVersion: k3d.io/v1alpha4\nkind: Simple\n"
+	cases := []struct {
+		name        string
+		ports       string
+		wantDrifted bool
+	}{
+		{"unchanged", "ports:\n  - port: 3080:80\n    nodeFilters: [loadbalancer]\n  - port: 127.0.0.1:3443:443/tcp\n    nodeFilters: [loadbalancer]\n", false},
+		{"port removed", "ports:\n  - port: 3080:80\n    nodeFilters: [loadbalancer]\n", true},
+		{"host port changed", "ports:\n  - port: 3081:80\n    nodeFilters: [loadbalancer]\n  - port: 127.0.0.1:3443:443\n    nodeFilters: [loadbalancer]\n", true},
+		{"no ports", "", true},
+		{"random host port", "ports:\n  - port: \"80\"\n    nodeFilters: [loadbalancer]\n", false},
+		{"other node filter", "ports:\n  - port: 3080:80\n    nodeFilters: [agent:0]\n", false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, drifted, err := reconcileK3dConfig(base+c.ports, cluster)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if drifted != c.wantDrifted {
+				t.Errorf("expected drifted %t, got %t", c.wantDrifted, drifted)
+			}
+			if !c.wantDrifted {
+				return
+			}
+			// The observed ports replace the configured ones.
+			config, err := parseK3dConfig(got)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if ports, ok := k3dConfigPortDrift(config, cluster); ok {
+				t.Errorf("expected reconciled ports to match the cluster, got %v", ports)
+			}
+		})
+	}
+}
+
+func TestReconcileK3dConfig(t *testing.T) {
+	cluster := K3dClusterInfo{
+		Name:         "test",
+		ServersCount: 1,
+		AgentsCount:  1,
+		Nodes: []K3dNodeInfo{
+			{Name: "k3d-test-server-0", Role: "server", Image: "rancher/k3s:v1.24.4-k3s1"},
+			{Name: "k3d-test-agent-0", Role: "agent", Image: "rancher/k3s:v1.24.4-k3s1"},
+		},
+	}
+	cases := []struct {
+		name        string
+		config      string
+		want        string
+		wantDrifted bool
+	}{
+		{"unchanged", "apiVersion: k3d.io/v1alpha4\nkind: Simple\nagents: 1\n", "apiVersion: k3d.io/v1alpha4\nkind: Simple\nagents: 1\n", false},
+		{"agent deleted", "apiVersion: k3d.io/v1alpha4\nkind: Simple\nagents: 2\n", "apiVersion: k3d.io/v1alpha4\nkind: Simple\nagents: 1\n", true},
+		{"agent added", "apiVersion: k3d.io/v1alpha4\nkind: Simple\n", "apiVersion: k3d.io/v1alpha4\nkind: Simple\nagents: 1\n", true},
+		{"image changed", "apiVersion: k3d.io/v1alpha4\nkind: Simple\nagents: 1\nimage: rancher/k3s:v1.25.4-k3s1\n", "apiVersion: k3d.io/v1alpha4\nkind: Simple\nagents: 1\nimage: rancher/k3s:v1.24.4-k3s1\n", true},
+		{"image channel", "apiVersion: k3d.io/v1alpha4\nkind: Simple\nagents: 1\nimage: +stable\n", "apiVersion: k3d.io/v1alpha4\nkind: Simple\nagents: 1\nimage: +stable\n", false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, drifted, err := reconcileK3dConfig(c.config, cluster)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if drifted != c.wantDrifted {
+				t.Errorf("expected drifted %t, got %t", c.wantDrifted, drifted)
+			}
+			if got != c.want {
+				t.Errorf("expected config %q, got %q", c.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,202 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces
+var _ datasource.DataSource = &KubeconfigDataSource{}
+var _ datasource.DataSourceWithConfigure = &KubeconfigDataSource{}
+var _ datasource.DataSourceWithValidateConfig = &KubeconfigDataSource{}
+
+func NewKubeconfigDataSource() datasource.DataSource {
+	return &KubeconfigDataSource{}
+}
+
+// KubeconfigDataSource defines the data source implementation.
+type KubeconfigDataSource struct {
+	runner K3dRunner
+}
+
+// KubeconfigDataSourceModel describes the data source data model.
+type KubeconfigDataSourceModel struct {
+	ID                   types.String `tfsdk:"id"`
+	Name                 types.String `tfsdk:"name"`
+	APIHostOverride      types.String `tfsdk:"api_host_override"`
+	Kubeconfig           types.String `tfsdk:"kubeconfig"`
+	ContextName          types.String `tfsdk:"context_name"`
+	ClusterName          types.String `tfsdk:"cluster_name"`
+	Host                 types.String `tfsdk:"host"`
+	ClientCertificate    types.String `tfsdk:"client_certificate"`
+	ClientKey            types.String `tfsdk:"client_key"`
+	ClusterCACertificate types.String `tfsdk:"cluster_ca_certificate"`
+	Token                types.String `tfsdk:"token"`
+}
+
+func (d *KubeconfigDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_kubeconfig"
+}
+
+func (d *KubeconfigDataSource) GetSchema(ctx context.Context) (tfsdk.Schema, diag.Diagnostics) {
+	return tfsdk.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "The data source `k3d_kubeconfig` reads the kubeconfig of a k3d cluster " +
+			"with `k3d kubeconfig get` every time Terraform reads it.\n" +
+			"\n" +
+			"Use it together with `store_credentials = false` on the `k3d_cluster` resource " +
+			"to keep the credentials of the cluster out of the resource state. " +
+			"Terraform still writes the results of data sources to the state file, " +
+			"but they are read again on every plan instead of being kept from the apply that created the cluster.",
+
+		Attributes: map[string]tfsdk.Attribute{
+			"id": {
+				MarkdownDescription: "Used internally by the provider.",
+				Type:                types.StringType,
+				Computed:            true,
+			},
+			"name": {
+				MarkdownDescription: "Cluster name.",
+				Required:            true,
+				Type:                types.StringType,
+			},
+			"api_host_override": {
+				MarkdownDescription: "Host name or IP address, with an optional port, to connect to the API server through " +
+					"instead of the address k3d writes to the kubeconfig. " +
+					"Overrides the server in `host` and `kubeconfig`. " +
+					"Use the same value as `api_host_override` of the `k3d_cluster` resource.",
+				Optional: true,
+				Type:     types.StringType,
+			},
+			"kubeconfig": {
+				MarkdownDescription: "Kubeconfig content.",
+				Type:                types.StringType,
+				Computed:            true,
+				Sensitive:           true,
+			},
+			"context_name": {
+				MarkdownDescription: "Name of the current context of the kubeconfig, such as `k3d-example`.",
+				Type:                types.StringType,
+				Computed:            true,
+			},
+			"cluster_name": {
+				MarkdownDescription: "Name of the cluster of the current context in the kubeconfig, such as `k3d-example`.",
+				Type:                types.StringType,
+				Computed:            true,
+			},
+			"host": {
+				MarkdownDescription: "Cluster host. " +
+					"Pass to `host` attribute when " +
+					"[configuring Kubernetes](https://registry.terraform.io/providers/hashicorp/kubernetes/latest/docs/guides/getting-started#provider-setup) " +
+					"or [Helm providers](https://registry.terraform.io/providers/hashicorp/helm/latest/docs#credentials-config).",
+				Type:     types.StringType,
+				Computed: true,
+			},
+			"client_certificate": {
+				MarkdownDescription: "Client certificate encoded in base 64. " +
+					"Use `base64decode` and pass to `client_certificate` attribute when " +
+					"[configuring Kubernetes](https://registry.terraform.io/providers/hashicorp/kubernetes/latest/docs/guides/getting-started#provider-setup) " +
+					"or [Helm providers](https://registry.terraform.io/providers/hashicorp/helm/latest/docs#credentials-config).",
+				Type:      types.StringType,
+				Computed:  true,
+				Sensitive: true,
+			},
+			"client_key": {
+				MarkdownDescription: "Client key encoded in base 64. " +
+					"Use `base64decode` and pass to `client_key` attribute when " +
+					"[configuring Kubernetes](https://registry.terraform.io/providers/hashicorp/kubernetes/latest/docs/guides/getting-started#provider-setup) " +
+					"or [Helm providers](https://registry.terraform.io/providers/hashicorp/helm/latest/docs#credentials-config).",
+				Type:      types.StringType,
+				Computed:  true,
+				Sensitive: true,
+			},
+			"cluster_ca_certificate": {
+				MarkdownDescription: "Cluster CA certificate encoded in base 64. " +
+					"Use `base64decode` and pass to `cluster_ca_certificate` attribute when " +
+					"[configuring Kubernetes](https://registry.terraform.io/providers/hashicorp/kubernetes/latest/docs/guides/getting-started#provider-setup) " +
+					"or [Helm providers](https://registry.terraform.io/providers/hashicorp/helm/latest/docs#credentials-config).",
+				Type:      types.StringType,
+				Computed:  true,
+				Sensitive: true,
+			},
+			"token": {
+				MarkdownDescription: "Bearer token of the kubeconfig user. " +
+					"Empty when the user authenticates with a client certificate. " +
+					"Users authenticating with an exec plugin have neither a token nor a client certificate, " +
+					"use `kubeconfig` for them instead.",
+				Type:      types.StringType,
+				Computed:  true,
+				Sensitive: true,
+			},
+		},
+	}, nil
+}
+
+func (d *KubeconfigDataSource) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	resp.Diagnostics.Append(validateClusterAPIHost(ctx, req.Config)...)
+}
+
+func (d *KubeconfigDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	runner, ok := req.ProviderData.(K3dRunner)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected K3dRunner, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.runner = runner
+}
+
+func (d *KubeconfigDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data KubeconfigDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	credentials, content, diags := getKubeconfig(ctx, d.runner, data.Name.ValueString())
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !data.APIHostOverride.IsNull() {
+		var err error
+		content, err = applyAPIHostOverride(&credentials, content, data.APIHostOverride.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("api_host_override"), "Failed overriding API host", fmt.Sprint(err))
+			return
+		}
+	}
+
+	data.ID = data.Name
+	data.ContextName = types.StringValue(credentials.ContextName)
+	data.ClusterName = types.StringValue(credentials.ClusterName)
+	data.Host = types.StringValue(credentials.Cluster.Server)
+	data.ClusterCACertificate = types.StringValue(credentials.Cluster.CertificateAuthorityData)
+	data.ClientCertificate = types.StringValue(credentials.User.ClientCertificateData)
+	data.ClientKey = types.StringValue(credentials.User.ClientKeyData)
+	data.Token = types.StringValue(credentials.User.Token)
+	data.Kubeconfig = types.StringValue(content)
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -0,0 +1,104 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func TestKubeconfigDataSourceRead(t *testing.T) {
+	runner := (&fakeK3dRunner{}).
+		On(testKubeconfig, nil, "kubeconfig", "get", "test")
+	d := &KubeconfigDataSource{runner: runner}
+
+	config, state := newTestKubeconfigDataSourceConfig(t, KubeconfigDataSourceModel{Name: types.StringValue("test")})
+	resp := &datasource.ReadResponse{State: state}
+	d.Read(context.Background(), datasource.ReadRequest{Config: config}, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+	data := getTestKubeconfigDataSourceModel(t, resp.State)
+	if got := data.Host.ValueString(); got != "https://0.0.0.0:40123" {
+		t.Errorf("expected host https://0.0.0.0:40123, got %s", got)
+	}
+	if got := data.ClientKey.ValueString(); got != "a2V5LWRhdGE=" {
+		t.Errorf("expected client_key a2V5LWRhdGE=, got %s", got)
+	}
+	if got := data.ContextName.ValueString(); got != "k3d-test" {
+		t.Errorf("expected context_name k3d-test, got %s", got)
+	}
+	if got := data.Kubeconfig.ValueString(); got != testKubeconfig {
+		t.Errorf("expected kubeconfig to match k3d output, got %s", got)
+	}
+}
+
+func TestKubeconfigDataSourceReadAPIHostOverride(t *testing.T) {
+	runner := (&fakeK3dRunner{}).
+		On(testKubeconfig, nil, "kubeconfig", "get", "test")
+	d := &KubeconfigDataSource{runner: runner}
+
+	config, state := newTestKubeconfigDataSourceConfig(t, KubeconfigDataSourceModel{
+		Name:            types.StringValue("test"),
+		APIHostOverride: types.StringValue("host.docker.internal"),
+	})
+	resp := &datasource.ReadResponse{State: state}
+	d.Read(context.Background(), datasource.ReadRequest{Config: config}, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+	data := getTestKubeconfigDataSourceModel(t, resp.State)
+	if got := data.Host.ValueString(); got != "https://host.docker.internal:40123" {
+		t.Errorf("expected overridden host, got %s", got)
+	}
+	if !strings.Contains(data.Kubeconfig.ValueString(), "server: https://host.docker.internal:40123") {
+		t.Errorf("expected overridden server in kubeconfig, got %s", data.Kubeconfig.ValueString())
+	}
+}
+
+func TestKubeconfigDataSourceReadMissing(t *testing.T) {
+	runner := (&fakeK3dRunner{}).
+		On("ERRO[0000] Failed to get cluster 'test'", errors.New("exit status 1"), "kubeconfig", "get", "test")
+	d := &KubeconfigDataSource{runner: runner}
+
+	config, state := newTestKubeconfigDataSourceConfig(t, KubeconfigDataSourceModel{Name: types.StringValue("test")})
+	resp := &datasource.ReadResponse{State: state}
+	d.Read(context.Background(), datasource.ReadRequest{Config: config}, resp)
+
+	if !resp.Diagnostics.HasError() {
+		t.Fatal("expected error")
+	}
+}
+
+// newTestKubeconfigDataSourceConfig returns a config holding data and an
+// empty state.
+func newTestKubeconfigDataSourceConfig(t *testing.T, data KubeconfigDataSourceModel) (tfsdk.Config, tfsdk.State) {
+	schema, diags := (&KubeconfigDataSource{}).GetSchema(context.Background())
+	if diags.HasError() {
+		t.Fatalf("unexpected schema error: %v", diags)
+	}
+	state := tfsdk.State{
+		Schema: schema,
+		Raw:    tftypes.NewValue(schema.Type().TerraformType(context.Background()), nil),
+	}
+	config := state
+	if diags := config.Set(context.Background(), &data); diags.HasError() {
+		t.Fatalf("unexpected config error: %v", diags)
+	}
+	return tfsdk.Config{Schema: schema, Raw: config.Raw}, state
+}
+
+func getTestKubeconfigDataSourceModel(t *testing.T, state tfsdk.State) KubeconfigDataSourceModel {
+	var data KubeconfigDataSourceModel
+	if diags := state.Get(context.Background(), &data); diags.HasError() {
+		t.Fatalf("unexpected state error: %v", diags)
+	}
+	return data
+}
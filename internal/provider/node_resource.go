@@ -0,0 +1,489 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// nodeResourceLabel is the runtime label of nodes managed by k3d_node
+// resources. These nodes are not counted as nodes of the cluster config, so
+// k3d_cluster resources neither report them as drift nor scale them down.
+const nodeResourceLabel = "terraform-provider-k3d.node"
+
+// Runtime labels recording the configuration k3d does not report for a node,
+// so it can be read back when the node is imported.
+const (
+	// nodeMemoryLabel holds the memory limit of the node.
+	nodeMemoryLabel = nodeResourceLabel + ".memory"
+	// nodeLabelsLabel holds the comma separated keys of the configured
+	// runtime labels, telling them apart from the labels added by k3d.
+	nodeLabelsLabel = nodeResourceLabel + ".labels"
+	// nodeK3sNodeLabelPrefix prefixes each k3s node label of the node.
+	nodeK3sNodeLabelPrefix = nodeResourceLabel + ".k3s-node-label."
+)
+
+// Ensure provider defined types fully satisfy framework interfaces
+var _ resource.Resource = &NodeResource{}
+var _ resource.ResourceWithImportState = &NodeResource{}
+var _ resource.ResourceWithValidateConfig = &NodeResource{}
+
+func NewNodeResource() resource.Resource {
+	return &NodeResource{}
+}
+
+// NodeResource defines the resource implementation.
+type NodeResource struct {
+	runner K3dRunner
+}
+
+// NodeResourceModel describes the resource data model.
+type NodeResourceModel struct {
+	ID            types.String `tfsdk:"id"`
+	Name          types.String `tfsdk:"name"`
+	Cluster       types.String `tfsdk:"cluster"`
+	Role          types.String `tfsdk:"role"`
+	Image         types.String `tfsdk:"image"`
+	Memory        types.String `tfsdk:"memory"`
+	Labels        types.Map    `tfsdk:"labels"`
+	K3sNodeLabels types.Map    `tfsdk:"k3s_node_labels"`
+	Running       types.Bool   `tfsdk:"running"`
+}
+
+func (r *NodeResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_node"
+}
+
+func (r *NodeResource) GetSchema(ctx context.Context) (tfsdk.Schema, diag.Diagnostics) {
+	return tfsdk.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "The resource `k3d_node` manages a single node of an existing k3d cluster, " +
+			"so nodes with their own image, labels or memory limit can be added to and removed from a cluster " +
+			"without recreating it.\n" +
+			"\n" +
+			"Nodes managed by this resource are not counted as `servers` and `agents` of the `k3d_cluster`. " +
+			"k3d does not support updating nodes, so changing any attribute replaces the node. " +
+			"Nodes created by this resource can be imported by container name, such as `k3d-example-0`. " +
+			"k3d does not report the memory limit, labels and k3s node labels of nodes, " +
+			"so the resource records them in runtime labels of the node container named `terraform-provider-k3d.node.*`, " +
+			"which are read back on import. " +
+			"Other nodes belong to the `servers` and `agents` of their cluster and can not be imported.",
+
+		Attributes: map[string]tfsdk.Attribute{
+			"id": {
+				MarkdownDescription: "Name of the node container.",
+				Type:                types.StringType,
+				Computed:            true,
+				PlanModifiers: tfsdk.AttributePlanModifiers{
+					resource.UseStateForUnknown(),
+				},
+			},
+			"name": {
+				MarkdownDescription: "Node name. k3d names the node container `k3d-<name>-0`. " +
+					"Changing the name forces replacement.",
+				Type:     types.StringType,
+				Required: true,
+				PlanModifiers: tfsdk.AttributePlanModifiers{
+					resource.RequiresReplace(),
+				},
+			},
+			"cluster": {
+				MarkdownDescription: "Name of the cluster to add the node to. Changing the cluster forces replacement.",
+				Type:                types.StringType,
+				Required:            true,
+				PlanModifiers: tfsdk.AttributePlanModifiers{
+					resource.RequiresReplace(),
+				},
+			},
+			"role": {
+				MarkdownDescription: "Node role, either `agent` or `server`. Defaults to `agent`. " +
+					"Changing the role forces replacement.",
+				Type:     types.StringType,
+				Optional: true,
+				Computed: true,
+				PlanModifiers: tfsdk.AttributePlanModifiers{
+					resource.UseStateForUnknown(),
+					resource.RequiresReplace(),
+				},
+			},
+			"image": {
+				MarkdownDescription: "k3s image of the node. Defaults to the image of the installed k3d version. " +
+					"Changing the image forces replacement.",
+				Type:     types.StringType,
+				Optional: true,
+				Computed: true,
+				PlanModifiers: tfsdk.AttributePlanModifiers{
+					resource.UseStateForUnknown(),
+					resource.RequiresReplace(),
+				},
+			},
+			"memory": {
+				MarkdownDescription: "Memory limit of the node container, such as `512m` or `2g`. " +
+					"Changing the limit forces replacement.",
+				Type:     types.StringType,
+				Optional: true,
+				PlanModifiers: tfsdk.AttributePlanModifiers{
+					resource.RequiresReplace(),
+				},
+			},
+			"labels": {
+				MarkdownDescription: "Labels of the node container. Changing the labels forces replacement.",
+				Type:                types.MapType{ElemType: types.StringType},
+				Optional:            true,
+				PlanModifiers: tfsdk.AttributePlanModifiers{
+					resource.RequiresReplace(),
+				},
+			},
+			"k3s_node_labels": {
+				MarkdownDescription: "Labels of the Kubernetes node registered by k3s. " +
+					"Changing the labels forces replacement.",
+				Type:     types.MapType{ElemType: types.StringType},
+				Optional: true,
+				PlanModifiers: tfsdk.AttributePlanModifiers{
+					resource.RequiresReplace(),
+				},
+			},
+			"running": {
+				MarkdownDescription: "Whether the node container is running.",
+				Type:                types.BoolType,
+				Computed:            true,
+				PlanModifiers: tfsdk.AttributePlanModifiers{
+					resource.UseStateForUnknown(),
+				},
+			},
+		},
+	}, nil
+}
+
+func (r *NodeResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	runner, ok := req.ProviderData.(K3dRunner)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected K3dRunner, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.runner = runner
+}
+
+func (r *NodeResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var role types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("role"), &role)...)
+	if resp.Diagnostics.HasError() || role.IsNull() || role.IsUnknown() {
+		return
+	}
+	if role.ValueString() != "agent" && role.ValueString() != "server" {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("role"),
+			"Invalid node role",
+			fmt.Sprintf("Expected \"agent\" or \"server\", got: %q.", role.ValueString()))
+	}
+}
+
+func (r *NodeResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data *NodeResourceModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if data.Role.IsNull() || data.Role.IsUnknown() {
+		data.Role = types.StringValue("agent")
+	}
+	args := []string{
+		"node", "create", data.Name.ValueString(),
+		"--cluster", data.Cluster.ValueString(),
+		"--role", data.Role.ValueString(),
+		"--runtime-label", nodeResourceLabel + "=true",
+		"--wait",
+	}
+	if !data.Image.IsNull() && !data.Image.IsUnknown() {
+		args = append(args, "--image", data.Image.ValueString())
+	}
+	if !data.Memory.IsNull() {
+		args = append(args, "--memory", data.Memory.ValueString())
+	}
+	labels, diags := nodeLabelArgs(ctx, "--runtime-label", data.Labels)
+	resp.Diagnostics.Append(diags...)
+	k3sNodeLabels, diags := nodeLabelArgs(ctx, "--k3s-node-label", data.K3sNodeLabels)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	records, diags := nodeRecordArgs(ctx, data)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	args = append(append(append(args, labels...), k3sNodeLabels...), records...)
+
+	output, err := r.runner.Run(withK3dPhase(ctx, "create"), args...)
+	if err != nil {
+		addK3dError(&resp.Diagnostics, "Failed creating k3d node", k3dObject{
+			Kind:     "node",
+			Name:     nodeContainerName(data.Name.ValueString()),
+			NamePath: path.Root("name"),
+		}, output, err)
+		return
+	}
+	tflog.Info(ctx, "created node", map[string]interface{}{"name": data.Cluster.ValueString(), "node": data.Name.ValueString()})
+
+	nodes, diags := listNodes(ctx, r.runner)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	node, err := findNode(nodes, nodeContainerName(data.Name.ValueString()))
+	if err != nil {
+		resp.Diagnostics.AddError("Failed reading created k3d node", fmt.Sprint(err))
+		return
+	}
+	data.ID = types.StringValue(node.Name)
+	setNodeAttributes(data, node)
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *NodeResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data *NodeResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	nodes, diags := listNodes(ctx, r.runner)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	// The node is gone when it or its cluster was deleted.
+	node, err := findNode(nodes, data.ID.ValueString())
+	if err != nil {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	setNodeAttributes(data, node)
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *NodeResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data *NodeResourceModel
+
+	// k3d can not change existing nodes, so all node attributes force
+	// replacement and the plan only needs to be saved.
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *NodeResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data *NodeResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if output, err := r.runner.Run(withK3dPhase(ctx, "delete"), "node", "delete", data.ID.ValueString()); err != nil {
+		addK3dError(&resp.Diagnostics, "Failed deleting k3d node", k3dObject{Kind: "node", Name: data.ID.ValueString()}, output, err)
+		return
+	}
+}
+
+func (r *NodeResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	// The other attributes are set by Read after the import.
+	name := strings.TrimSuffix(strings.TrimPrefix(req.ID, "k3d-"), "-0")
+
+	// Nodes without the runtime label are counted as nodes of the cluster,
+	// and runtime labels can not be added to existing containers.
+	nodes, diags := listNodes(ctx, r.runner)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	node, err := findNode(nodes, nodeContainerName(name))
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Cannot import non-existent k3d node",
+			fmt.Sprintf("A k3d node named %q does not exist. List existing nodes with `k3d node list`.", nodeContainerName(name)))
+		return
+	}
+	if node.RuntimeLabels[nodeResourceLabel] == "" {
+		resp.Diagnostics.AddError(
+			"Cannot import k3d node of the cluster",
+			fmt.Sprintf("The k3d node %q was not created by a `k3d_node` resource, so it belongs to the `servers` or `agents` of its cluster. "+
+				"Only nodes with the `%s` runtime label can be imported, and k3d can not add runtime labels to existing nodes. "+
+				"Scale the cluster with `k3d_config` instead, or create the node with a `k3d_node` resource.", node.Name, nodeResourceLabel))
+		return
+	}
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), nodeContainerName(name))...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("name"), name)...)
+}
+
+// listNodes lists the k3d nodes of all clusters.
+func listNodes(ctx context.Context, runner K3dRunner) ([]K3dNodeInfo, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	output, err := runner.Run(ctx, "node", "list", "--output", "json")
+	if err != nil {
+		addK3dError(&diags, "Failed listing k3d nodes", k3dObject{}, output, err)
+		return nil, diags
+	}
+	// Without any clusters or registries, `k3d node list` has no output.
+	if strings.TrimSpace(string(output)) == "" {
+		return nil, diags
+	}
+	var nodes []K3dNodeInfo
+	if err := json.Unmarshal(output, &nodes); err != nil {
+		diags.AddError("Failed parsing k3d node list", fmt.Sprint(err))
+		return nil, diags
+	}
+	return nodes, diags
+}
+
+func findNode(nodes []K3dNodeInfo, containerName string) (K3dNodeInfo, error) {
+	for _, node := range nodes {
+		if node.Name == containerName {
+			return node, nil
+		}
+	}
+	return K3dNodeInfo{}, fmt.Errorf("nodes does not contain a node with matching name")
+}
+
+// nodeContainerName returns the name of the container of the node called
+// name. k3d prefixes it with `k3d-` and suffixes it with the replica number.
+func nodeContainerName(name string) string {
+	return fmt.Sprintf("k3d-%s-0", name)
+}
+
+// nodeLabelArgs returns the k3d flag for each label of labels, in order of
+// the label keys.
+func nodeLabelArgs(ctx context.Context, flag string, labels types.Map) ([]string, diag.Diagnostics) {
+	if labels.IsNull() || labels.IsUnknown() {
+		return nil, nil
+	}
+	values := map[string]string{}
+	diags := labels.ElementsAs(ctx, &values, false)
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var args []string
+	for _, key := range keys {
+		args = append(args, flag, key+"="+values[key])
+	}
+	return args, diags
+}
+
+// nodeRecordArgs returns the runtime label flags recording the memory limit,
+// the label keys and the k3s node labels of data on the node container.
+func nodeRecordArgs(ctx context.Context, data *NodeResourceModel) ([]string, diag.Diagnostics) {
+	var args []string
+	if !data.Memory.IsNull() && !data.Memory.IsUnknown() {
+		args = append(args, "--runtime-label", nodeMemoryLabel+"="+data.Memory.ValueString())
+	}
+	if !data.Labels.IsNull() && !data.Labels.IsUnknown() && len(data.Labels.Elements()) > 0 {
+		keys := make([]string, 0, len(data.Labels.Elements()))
+		for key := range data.Labels.Elements() {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		args = append(args, "--runtime-label", nodeLabelsLabel+"="+strings.Join(keys, ","))
+	}
+	k3sNodeLabels, diags := nodeLabelArgs(ctx, "--runtime-label", data.K3sNodeLabels)
+	for i := 1; i < len(k3sNodeLabels); i += 2 {
+		k3sNodeLabels[i] = nodeK3sNodeLabelPrefix + k3sNodeLabels[i]
+	}
+	return append(args, k3sNodeLabels...), diags
+}
+
+// setNodeAttributes sets the attributes observed on the node container on
+// data.
+func setNodeAttributes(data *NodeResourceModel, node K3dNodeInfo) {
+	data.Name = types.StringValue(strings.TrimSuffix(strings.TrimPrefix(node.Name, "k3d-"), "-0"))
+	data.Role = types.StringValue(node.Role)
+	if cluster := node.RuntimeLabels["k3d.cluster"]; cluster != "" {
+		data.Cluster = types.StringValue(cluster)
+	}
+	// Nodes without a configured image use the k3s image of the k3d release.
+	data.Image = containerImage(data.Image, node)
+	data.Running = types.BoolValue(node.State.Running)
+
+	// The container has labels added by k3d and the image besides the
+	// configured ones, so only the configured labels are observed.
+	if !data.Labels.IsNull() && !data.Labels.IsUnknown() {
+		labels := map[string]attr.Value{}
+		for key := range data.Labels.Elements() {
+			if value, ok := node.RuntimeLabels[key]; ok {
+				labels[key] = types.StringValue(value)
+			}
+		}
+		data.Labels = types.MapValueMust(types.StringType, labels)
+	}
+
+	// k3d does not report the memory limit, labels and k3s node labels of
+	// imported nodes, so they are read from the runtime labels recorded on
+	// create.
+	if data.Memory.IsNull() {
+		if memory, ok := node.RuntimeLabels[nodeMemoryLabel]; ok {
+			data.Memory = types.StringValue(memory)
+		}
+	}
+	if data.Labels.IsNull() && node.RuntimeLabels[nodeLabelsLabel] != "" {
+		labels := map[string]attr.Value{}
+		for _, key := range strings.Split(node.RuntimeLabels[nodeLabelsLabel], ",") {
+			if value, ok := node.RuntimeLabels[key]; ok {
+				labels[key] = types.StringValue(value)
+			}
+		}
+		data.Labels = types.MapValueMust(types.StringType, labels)
+	}
+	if data.K3sNodeLabels.IsNull() {
+		k3sNodeLabels := map[string]attr.Value{}
+		for key, value := range node.RuntimeLabels {
+			if strings.HasPrefix(key, nodeK3sNodeLabelPrefix) {
+				k3sNodeLabels[strings.TrimPrefix(key, nodeK3sNodeLabelPrefix)] = types.StringValue(value)
+			}
+		}
+		if len(k3sNodeLabels) > 0 {
+			data.K3sNodeLabels = types.MapValueMust(types.StringType, k3sNodeLabels)
+		}
+	}
+}
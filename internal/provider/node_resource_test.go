@@ -0,0 +1,263 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	fwresource "github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+const testNodeResourceList = `[
+	{
+		"name": "k3d-test-server-0",
+		"role": "server",
+		"image": "rancher/k3s:v1.24.4-k3s1",
+		"runtimeLabels": {"k3d.cluster": "test", "k3d.role": "server"},
+		"State": {"Running": true, "Status": "running"}
+	},
+	{
+		"name": "k3d-extra-0",
+		"role": "agent",
+		"image": "rancher/k3s:v1.24.4-k3s1",
+		"runtimeLabels": {"k3d.cluster": "test", "k3d.role": "agent", "terraform-provider-k3d.node": "true", "team": "infra",
+			"terraform-provider-k3d.node.memory": "1g", "terraform-provider-k3d.node.labels": "team",
+			"terraform-provider-k3d.node.k3s-node-label.gpu": "true"},
+		"State": {"Running": true, "Status": "running"}
+	}
+]`
+
+func TestNodeResourceCreate(t *testing.T) {
+	runner := (&fakeK3dRunner{}).
+		On("", nil, "node", "create", "extra").
+		On(testNodeResourceList, nil, "node", "list")
+	r := &NodeResource{runner: runner}
+
+	req := fwresource.CreateRequest{Plan: newTestPlan(t, testResourceSchema(t, &NodeResource{}), NodeResourceModel{
+		ID:            types.StringUnknown(),
+		Name:          types.StringValue("extra"),
+		Cluster:       types.StringValue("test"),
+		Role:          types.StringUnknown(),
+		Image:         types.StringUnknown(),
+		Memory:        types.StringValue("1g"),
+		Labels:        types.MapValueMust(types.StringType, map[string]attr.Value{"team": types.StringValue("infra")}),
+		K3sNodeLabels: types.MapValueMust(types.StringType, map[string]attr.Value{"gpu": types.StringValue("true")}),
+		Running:       types.BoolUnknown(),
+	})}
+	resp := &fwresource.CreateResponse{State: newTestState(t, testResourceSchema(t, &NodeResource{}), nil)}
+	r.Create(context.Background(), req, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+	if !runner.Called("node", "create", "extra", "--cluster", "test", "--role", "agent",
+		"--runtime-label", "terraform-provider-k3d.node=true", "--wait", "--memory", "1g",
+		"--runtime-label", "team=infra", "--k3s-node-label", "gpu=true",
+		"--runtime-label", "terraform-provider-k3d.node.memory=1g",
+		"--runtime-label", "terraform-provider-k3d.node.labels=team",
+		"--runtime-label", "terraform-provider-k3d.node.k3s-node-label.gpu=true") {
+		t.Errorf("expected node create with role, memory and labels, got %v", runner.calls)
+	}
+	data := getTestModel[NodeResourceModel](t, resp.State)
+	if got := data.ID.ValueString(); got != "k3d-extra-0" {
+		t.Errorf("expected id k3d-extra-0, got %s", got)
+	}
+	if got := data.Image.ValueString(); got != "rancher/k3s:v1.24.4-k3s1" {
+		t.Errorf("expected default image, got %s", got)
+	}
+	if !data.Running.ValueBool() {
+		t.Error("expected running to be true")
+	}
+}
+
+func TestNodeResourceRead(t *testing.T) {
+	runner := (&fakeK3dRunner{}).
+		On(testNodeResourceList, nil, "node", "list")
+	r := &NodeResource{runner: runner}
+
+	// Imported nodes only have an ID and a name.
+	state := newTestState(t, testResourceSchema(t, &NodeResource{}), &NodeResourceModel{
+		ID:            types.StringValue("k3d-extra-0"),
+		Name:          types.StringValue("extra"),
+		Labels:        types.MapNull(types.StringType),
+		K3sNodeLabels: types.MapNull(types.StringType),
+	})
+	resp := &fwresource.ReadResponse{State: state}
+	r.Read(context.Background(), fwresource.ReadRequest{State: state}, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+	data := getTestModel[NodeResourceModel](t, resp.State)
+	if got := data.Cluster.ValueString(); got != "test" {
+		t.Errorf("expected cluster test from the node labels, got %s", got)
+	}
+	if got := data.Role.ValueString(); got != "agent" {
+		t.Errorf("expected role agent, got %s", got)
+	}
+	// The configuration recorded on create is read back, without the labels
+	// added by k3d.
+	if got := data.Memory.ValueString(); got != "1g" {
+		t.Errorf("expected memory 1g, got %s", got)
+	}
+	wantLabels := types.MapValueMust(types.StringType, map[string]attr.Value{"team": types.StringValue("infra")})
+	if !data.Labels.Equal(wantLabels) {
+		t.Errorf("expected labels %v, got %v", wantLabels, data.Labels)
+	}
+	wantK3sNodeLabels := types.MapValueMust(types.StringType, map[string]attr.Value{"gpu": types.StringValue("true")})
+	if !data.K3sNodeLabels.Equal(wantK3sNodeLabels) {
+		t.Errorf("expected k3s node labels %v, got %v", wantK3sNodeLabels, data.K3sNodeLabels)
+	}
+}
+
+func TestNodeResourceReadUnrecorded(t *testing.T) {
+	runner := (&fakeK3dRunner{}).
+		On(`[{"name": "k3d-extra-0", "role": "agent",
+			"runtimeLabels": {"k3d.cluster": "test", "terraform-provider-k3d.node": "true"}}]`, nil, "node", "list")
+	r := &NodeResource{runner: runner}
+
+	state := newTestState(t, testResourceSchema(t, &NodeResource{}), &NodeResourceModel{
+		ID:            types.StringValue("k3d-extra-0"),
+		Name:          types.StringValue("extra"),
+		Labels:        types.MapNull(types.StringType),
+		K3sNodeLabels: types.MapNull(types.StringType),
+	})
+	resp := &fwresource.ReadResponse{State: state}
+	r.Read(context.Background(), fwresource.ReadRequest{State: state}, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+	data := getTestModel[NodeResourceModel](t, resp.State)
+	if !data.Memory.IsNull() || !data.Labels.IsNull() || !data.K3sNodeLabels.IsNull() {
+		t.Errorf("expected unset memory and labels to stay null, got %v, %v and %v", data.Memory, data.Labels, data.K3sNodeLabels)
+	}
+}
+
+func TestNodeResourceReadMissing(t *testing.T) {
+	runner := (&fakeK3dRunner{}).
+		On("", nil, "node", "list")
+	r := &NodeResource{runner: runner}
+
+	state := newTestState(t, testResourceSchema(t, &NodeResource{}), &NodeResourceModel{
+		ID:            types.StringValue("k3d-extra-0"),
+		Name:          types.StringValue("extra"),
+		Cluster:       types.StringValue("test"),
+		Labels:        types.MapNull(types.StringType),
+		K3sNodeLabels: types.MapNull(types.StringType),
+	})
+	resp := &fwresource.ReadResponse{State: state}
+	r.Read(context.Background(), fwresource.ReadRequest{State: state}, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+	if !resp.State.Raw.IsNull() {
+		t.Error("expected resource to be removed from state")
+	}
+}
+
+func TestNodeResourceDelete(t *testing.T) {
+	runner := (&fakeK3dRunner{}).
+		On("", nil, "node", "delete", "k3d-extra-0")
+	r := &NodeResource{runner: runner}
+
+	state := newTestState(t, testResourceSchema(t, &NodeResource{}), &NodeResourceModel{
+		ID:            types.StringValue("k3d-extra-0"),
+		Name:          types.StringValue("extra"),
+		Cluster:       types.StringValue("test"),
+		Labels:        types.MapNull(types.StringType),
+		K3sNodeLabels: types.MapNull(types.StringType),
+	})
+	resp := &fwresource.DeleteResponse{State: state}
+	r.Delete(context.Background(), fwresource.DeleteRequest{State: state}, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+	if !runner.Called("node", "delete", "k3d-extra-0") {
+		t.Errorf("expected node delete, got %v", runner.calls)
+	}
+}
+
+func TestNodeResourceImportState(t *testing.T) {
+	runner := (&fakeK3dRunner{}).
+		On(testNodeResourceList, nil, "node", "list")
+	r := &NodeResource{runner: runner}
+
+	for _, id := range []string{"k3d-extra-0", "extra"} {
+		resp := &fwresource.ImportStateResponse{State: newTestState(t, testResourceSchema(t, &NodeResource{}), nil)}
+		r.ImportState(context.Background(), fwresource.ImportStateRequest{ID: id}, resp)
+
+		if resp.Diagnostics.HasError() {
+			t.Fatalf("unexpected error: %v", resp.Diagnostics)
+		}
+		data := getTestModel[NodeResourceModel](t, resp.State)
+		if data.ID.ValueString() != "k3d-extra-0" || data.Name.ValueString() != "extra" {
+			t.Errorf("expected id k3d-extra-0 and name extra for %q, got %s and %s", id, data.ID.ValueString(), data.Name.ValueString())
+		}
+	}
+}
+
+func TestNodeResourceImportStateClusterNode(t *testing.T) {
+	runner := (&fakeK3dRunner{}).
+		On(testNodeResourceList, nil, "node", "list")
+	r := &NodeResource{runner: runner}
+
+	for _, id := range []string{"k3d-test-server-0", "k3d-missing-0"} {
+		resp := &fwresource.ImportStateResponse{State: newTestState(t, testResourceSchema(t, &NodeResource{}), nil)}
+		r.ImportState(context.Background(), fwresource.ImportStateRequest{ID: id}, resp)
+
+		if !resp.Diagnostics.HasError() {
+			t.Errorf("expected error importing %s", id)
+		}
+	}
+}
+
+func TestNodeResourceValidateConfig(t *testing.T) {
+	r := &NodeResource{}
+
+	for role, wantError := range map[string]bool{"agent": false, "server": false, "loadbalancer": true} {
+		plan := newTestPlan(t, testResourceSchema(t, &NodeResource{}), NodeResourceModel{
+			Name:          types.StringValue("extra"),
+			Cluster:       types.StringValue("test"),
+			Role:          types.StringValue(role),
+			Labels:        types.MapNull(types.StringType),
+			K3sNodeLabels: types.MapNull(types.StringType),
+		})
+		req := fwresource.ValidateConfigRequest{Config: tfsdk.Config{Schema: plan.Schema, Raw: plan.Raw}}
+		resp := &fwresource.ValidateConfigResponse{}
+		r.ValidateConfig(context.Background(), req, resp)
+
+		if got := resp.Diagnostics.HasError(); got != wantError {
+			t.Errorf("expected error %t for role %s, got %v", wantError, role, resp.Diagnostics)
+		}
+	}
+}
+
+func TestClusterInfoWithoutNodeResources(t *testing.T) {
+	cluster := K3dClusterInfo{
+		Name:         "test",
+		ServersCount: 1,
+		AgentsCount:  2,
+		Nodes: []K3dNodeInfo{
+			{Name: "k3d-test-server-0", Role: "server"},
+			{Name: "k3d-test-agent-0", Role: "agent"},
+			{Name: "k3d-extra-0", Role: "agent", RuntimeLabels: map[string]string{nodeResourceLabel: "true"}},
+		},
+	}
+
+	if got := cluster.NodeCount("agent"); got != 1 {
+		t.Errorf("expected 1 agent, got %d", got)
+	}
+	if got := len(cluster.NodesWithRole("agent")); got != 1 {
+		t.Errorf("expected 1 agent node, got %d", got)
+	}
+	// Nodes of k3d_node resources are not drift of the cluster config.
+	config := "apiVersion: k3d.io/v1alpha4\nkind: Simple\nagents: 1\n"
+	if _, drifted, err := reconcileK3dConfig(config, cluster); err != nil || drifted {
+		t.Errorf("expected no drift, got %t, %v", drifted, err)
+	}
+}
@@ -0,0 +1,186 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined plan modifiers fully satisfy framework interfaces
+var _ tfsdk.AttributePlanModifier = ensureRunningModifier{}
+var _ tfsdk.AttributePlanModifier = k3dConfigRenderModifier{}
+var _ tfsdk.AttributePlanModifier = k3dConfigRequiresReplaceModifier{}
+var _ tfsdk.AttributePlanModifier = kubeconfigFileModifier{}
+
+// ensureRunningModifier plans the running attribute as true when
+// ensure_running is enabled, which shows stopped clusters as drift.
+type ensureRunningModifier struct{}
+
+func (m ensureRunningModifier) Description(ctx context.Context) string {
+	return "Plans the cluster as running when ensure_running is enabled."
+}
+
+func (m ensureRunningModifier) MarkdownDescription(ctx context.Context) string {
+	return "Plans the cluster as running when `ensure_running` is enabled."
+}
+
+func (m ensureRunningModifier) Modify(ctx context.Context, req tfsdk.ModifyAttributePlanRequest, resp *tfsdk.ModifyAttributePlanResponse) {
+	var ensureRunning types.Bool
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("ensure_running"), &ensureRunning)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if ensureRunning.ValueBool() {
+		resp.AttributePlan = types.BoolValue(true)
+	}
+}
+
+// k3dConfigRenderModifier plans k3d_config as the rendered structured config
+// when the config attribute is used instead of k3d_config.
+type k3dConfigRenderModifier struct{}
+
+func (m k3dConfigRenderModifier) Description(ctx context.Context) string {
+	return "Renders the structured config into k3d_config."
+}
+
+func (m k3dConfigRenderModifier) MarkdownDescription(ctx context.Context) string {
+	return "Renders the structured `config` into `k3d_config`."
+}
+
+func (m k3dConfigRenderModifier) Modify(ctx context.Context, req tfsdk.ModifyAttributePlanRequest, resp *tfsdk.ModifyAttributePlanResponse) {
+	var config types.Object
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("config"), &config)...)
+
+	if resp.Diagnostics.HasError() || config.IsNull() {
+		return
+	}
+
+	value, err := config.ToTerraformValue(ctx)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("config"), "Invalid config", fmt.Sprint(err))
+		return
+	}
+
+	// The config can only be rendered once all of its values are known.
+	if !value.IsFullyKnown() {
+		resp.AttributePlan = types.StringUnknown()
+		return
+	}
+
+	var model ClusterConfigModel
+	resp.Diagnostics.Append(config.As(ctx, &model, types.ObjectAsOptions{})...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	content, err := renderK3dConfig(model)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("config"), "Invalid config", fmt.Sprint(err))
+		return
+	}
+	resp.AttributePlan = types.StringValue(content)
+}
+
+// k3dConfigRequiresReplace requires replacing the cluster when k3d_config
+// changes in a way that cannot be applied in place. Changes of the amount of
+// agents, and added servers of clusters with multiple servers, are applied by
+// Update instead.
+func k3dConfigRequiresReplace() tfsdk.AttributePlanModifier {
+	return k3dConfigRequiresReplaceModifier{}
+}
+
+// k3dConfigRequiresReplaceModifier compares the planned k3d_config instead of
+// the configured one, because k3d_config is computed when the structured
+// config is used.
+type k3dConfigRequiresReplaceModifier struct{}
+
+func (m k3dConfigRequiresReplaceModifier) Description(ctx context.Context) string {
+	return "Changes other than the amount of agents, or adding servers to clusters with multiple servers, force replacement."
+}
+
+func (m k3dConfigRequiresReplaceModifier) MarkdownDescription(ctx context.Context) string {
+	return "Changes other than the amount of `agents`, or adding `servers` to clusters with multiple servers, force replacement."
+}
+
+func (m k3dConfigRequiresReplaceModifier) Modify(ctx context.Context, req tfsdk.ModifyAttributePlanRequest, resp *tfsdk.ModifyAttributePlanResponse) {
+	// Creating or deleting the resource never requires replacing it.
+	if req.State.Raw.IsNull() || req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var planned, prior types.String
+	resp.Diagnostics.Append(tfsdk.ValueAs(ctx, resp.AttributePlan, &planned)...)
+	resp.Diagnostics.Append(tfsdk.ValueAs(ctx, req.AttributeState, &prior)...)
+	if resp.Diagnostics.HasError() || planned.Equal(prior) {
+		return
+	}
+
+	if k3dConfigChangeRequiresReplace(prior, planned) {
+		resp.RequiresReplace = true
+	}
+}
+
+// k3dConfigChangeRequiresReplace reports whether changing k3d_config from
+// prior to planned replaces the cluster.
+func k3dConfigChangeRequiresReplace(prior types.String, planned types.String) bool {
+	if planned.Equal(prior) {
+		return false
+	}
+	// Unknown configs cannot be compared, so assume they cannot be applied
+	// in place.
+	if planned.IsUnknown() {
+		return true
+	}
+
+	// Configs k3d cannot parse are rejected on create of the replacement.
+	scalable, err := k3dConfigScalable(prior.ValueString(), planned.ValueString())
+	return err != nil || !scalable
+}
+
+// kubeconfigFileModifier plans kubeconfig_file_checksum as unknown when the
+// file at kubeconfig_path does not have the checksum, so the file is written
+// again by the next apply.
+type kubeconfigFileModifier struct{}
+
+func (m kubeconfigFileModifier) Description(ctx context.Context) string {
+	return "Plans writing the kubeconfig file again when it changed."
+}
+
+func (m kubeconfigFileModifier) MarkdownDescription(ctx context.Context) string {
+	return "Plans writing the kubeconfig file again when it changed."
+}
+
+func (m kubeconfigFileModifier) Modify(ctx context.Context, req tfsdk.ModifyAttributePlanRequest, resp *tfsdk.ModifyAttributePlanResponse) {
+	// The checksum is unknown already when the cluster is created or changed.
+	if req.AttributeState == nil || req.AttributeState.IsNull() || req.AttributePlan.IsUnknown() {
+		return
+	}
+
+	var kubeconfigPath types.String
+	var running types.Bool
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("kubeconfig_path"), &kubeconfigPath)...)
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("running"), &running)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The kubeconfig of stopped clusters is written once they are started.
+	if kubeconfigPath.IsNull() || !running.ValueBool() {
+		return
+	}
+
+	var checksum types.String
+	resp.Diagnostics.Append(tfsdk.ValueAs(ctx, req.AttributeState, &checksum)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if kubeconfigFileChanged(kubeconfigPath.ValueString(), checksum.ValueString()) {
+		resp.AttributePlan = types.StringUnknown()
+	}
+}
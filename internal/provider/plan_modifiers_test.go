@@ -0,0 +1,185 @@
+package provider
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestEnsureRunningModifier(t *testing.T) {
+	cases := []struct {
+		name          string
+		ensureRunning types.Bool
+		state         types.Bool
+		want          types.Bool
+	}{
+		{"enabled and stopped", types.BoolValue(true), types.BoolValue(false), types.BoolValue(true)},
+		{"disabled and stopped", types.BoolValue(false), types.BoolValue(false), types.BoolValue(false)},
+		{"unset and stopped", types.BoolNull(), types.BoolValue(false), types.BoolValue(false)},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			plan := newTestPlan(t, testResourceSchema(t, &ClusterResource{}), ClusterResourceModel{
+				Name:          types.StringValue("test"),
+				K3dConfig:     types.StringValue("apiVersion: k3d.io/v1alpha4\nkind: Simple\n"),
+				EnsureRunning: c.ensureRunning,
+			})
+			req := tfsdk.ModifyAttributePlanRequest{
+				AttributePath: path.Root("running"),
+				Config:        tfsdk.Config{Schema: plan.Schema, Raw: plan.Raw},
+				AttributePlan: c.state,
+			}
+			resp := &tfsdk.ModifyAttributePlanResponse{AttributePlan: c.state}
+			ensureRunningModifier{}.Modify(context.Background(), req, resp)
+
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected error: %v", resp.Diagnostics)
+			}
+			if !resp.AttributePlan.Equal(c.want) {
+				t.Errorf("expected plan %s, got %s", c.want, resp.AttributePlan)
+			}
+		})
+	}
+}
+
+func TestK3dConfigRequiresReplace(t *testing.T) {
+	base := "apiVersion: k3d.io/v1alpha4\nkind: Simple\nagents: 1\n"
+	cases := []struct {
+		name   string
+		config types.String
+		want   bool
+	}{
+		{"agents changed", types.StringValue("apiVersion: k3d.io/v1alpha4\nkind: Simple\nagents: 2\n"), false},
+		{"image changed", types.StringValue(base + "image: rancher/k3s:v1.25.4-k3s1\n"), true},
+		{"invalid", types.StringValue("kind: ["), true},
+		{"unknown", types.StringUnknown(), true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			state := newTestState(t, testResourceSchema(t, &ClusterResource{}), &ClusterResourceModel{
+				Name:      types.StringValue("test"),
+				K3dConfig: types.StringValue(base),
+			})
+			plan := newTestPlan(t, testResourceSchema(t, &ClusterResource{}), ClusterResourceModel{
+				Name:      types.StringValue("test"),
+				K3dConfig: c.config,
+			})
+			req := tfsdk.ModifyAttributePlanRequest{
+				AttributePath:   path.Root("k3d_config"),
+				Config:          tfsdk.Config{Schema: plan.Schema, Raw: plan.Raw},
+				Plan:            plan,
+				State:           state,
+				AttributeConfig: c.config,
+				AttributePlan:   c.config,
+				AttributeState:  types.StringValue(base),
+			}
+			resp := &tfsdk.ModifyAttributePlanResponse{AttributePlan: c.config}
+			k3dConfigRequiresReplace().Modify(context.Background(), req, resp)
+
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected error: %v", resp.Diagnostics)
+			}
+			if resp.RequiresReplace != c.want {
+				t.Errorf("expected requires replace %t, got %t", c.want, resp.RequiresReplace)
+			}
+		})
+	}
+}
+
+func TestK3dConfigRenderModifier(t *testing.T) {
+	plan := newTestPlan(t, testResourceSchema(t, &ClusterResource{}), ClusterResourceModel{
+		Name:   types.StringValue("test"),
+		Config: &ClusterConfigModel{Agents: types.Int64Value(2)},
+	})
+	req := tfsdk.ModifyAttributePlanRequest{
+		AttributePath: path.Root("k3d_config"),
+		Config:        tfsdk.Config{Schema: plan.Schema, Raw: plan.Raw},
+		Plan:          plan,
+		AttributePlan: types.StringUnknown(),
+	}
+	resp := &tfsdk.ModifyAttributePlanResponse{AttributePlan: types.StringUnknown()}
+	k3dConfigRenderModifier{}.Modify(context.Background(), req, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+	want := types.StringValue("apiVersion: k3d.io/v1alpha4\nkind: Simple\nservers: 1\nagents: 2\n")
+	if !resp.AttributePlan.Equal(want) {
+		t.Errorf("expected plan %s, got %s", want, resp.AttributePlan)
+	}
+}
+
+func TestK3dConfigRenderModifierUnknown(t *testing.T) {
+	plan := newTestPlan(t, testResourceSchema(t, &ClusterResource{}), ClusterResourceModel{
+		Name:   types.StringValue("test"),
+		Config: &ClusterConfigModel{},
+	})
+	diags := plan.SetAttribute(context.Background(), path.Root("config").AtName("image"), types.StringUnknown())
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	req := tfsdk.ModifyAttributePlanRequest{
+		AttributePath: path.Root("k3d_config"),
+		Config:        tfsdk.Config{Schema: plan.Schema, Raw: plan.Raw},
+		Plan:          plan,
+		AttributePlan: types.StringNull(),
+	}
+	resp := &tfsdk.ModifyAttributePlanResponse{AttributePlan: types.StringNull()}
+	k3dConfigRenderModifier{}.Modify(context.Background(), req, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+	if !resp.AttributePlan.IsUnknown() {
+		t.Errorf("expected unknown plan, got %s", resp.AttributePlan)
+	}
+}
+
+func TestKubeconfigFileModifier(t *testing.T) {
+	kubeconfigPath := filepath.Join(t.TempDir(), "test.yaml")
+	if err := writeKubeconfigFile(kubeconfigPath, testKubeconfig); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cases := []struct {
+		name        string
+		path        string
+		running     bool
+		wantUnknown bool
+	}{
+		{"unchanged", kubeconfigPath, true, false},
+		{"removed", kubeconfigPath + ".removed", true, true},
+		{"removed from stopped cluster", kubeconfigPath + ".removed", false, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			checksum := types.StringValue(kubeconfigChecksum(testKubeconfig))
+			state := newTestState(t, testResourceSchema(t, &ClusterResource{}), &ClusterResourceModel{
+				ID:                     types.StringValue("test"),
+				Name:                   types.StringValue("test"),
+				K3dConfig:              types.StringValue("apiVersion: k3d.io/v1alpha4\nkind: Simple\n"),
+				KubeconfigPath:         types.StringValue(c.path),
+				KubeconfigFileChecksum: checksum,
+				Running:                types.BoolValue(c.running),
+			})
+			req := tfsdk.ModifyAttributePlanRequest{
+				AttributePath:  path.Root("kubeconfig_file_checksum"),
+				State:          state,
+				AttributeState: checksum,
+				AttributePlan:  checksum,
+			}
+			resp := &tfsdk.ModifyAttributePlanResponse{AttributePlan: checksum}
+			kubeconfigFileModifier{}.Modify(context.Background(), req, resp)
+
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected error: %v", resp.Diagnostics)
+			}
+			if got := resp.AttributePlan.IsUnknown(); got != c.wantUnknown {
+				t.Errorf("expected unknown checksum %t, got %t", c.wantUnknown, got)
+			}
+		})
+	}
+}
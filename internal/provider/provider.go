@@ -2,13 +2,16 @@ package provider
 
 import (
 	"context"
-	"net/http"
+	"fmt"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
 // Ensure K3dProvider satisfies various provider interfaces.
@@ -24,7 +27,12 @@ type K3dProvider struct {
 }
 
 // K3dProviderModel describes the provider data model.
-type K3dProviderModel struct{}
+type K3dProviderModel struct {
+	K3dPath        types.String `tfsdk:"k3d_path"`
+	DockerHost     types.String `tfsdk:"docker_host"`
+	Env            types.Map    `tfsdk:"env"`
+	CommandTimeout types.String `tfsdk:"command_timeout"`
+}
 
 func (p *K3dProvider) Metadata(ctx context.Context, req provider.MetadataRequest, resp *provider.MetadataResponse) {
 	resp.TypeName = "k3d"
@@ -43,11 +51,37 @@ func (p *K3dProvider) GetSchema(ctx context.Context) (tfsdk.Schema, diag.Diagnos
 			"\n" +
 			"Make sure to install k3d, [see the installation guide](https://k3d.io/v5.4.6/#installation).\n" +
 			"\n" +
-			"You may need to run Terraform with `sudo` because k3d uses Docker.\n" +
+			"You may need to run Terraform with `sudo` because k3d uses Docker. " +
+			"Alternatively set `docker_host` to use rootless Docker or a remote Docker daemon.\n" +
 			"\n" +
 			"The example below creates a cluster and deploys a Postgres instance on it. " +
 			"It can be adapted to deploy any services your app needs for development with minimal effort.",
-		Attributes: map[string]tfsdk.Attribute{},
+		Attributes: map[string]tfsdk.Attribute{
+			"k3d_path": {
+				MarkdownDescription: "Path to the k3d binary. " +
+					"Defaults to `k3d` looked up in `$PATH`.",
+				Optional: true,
+				Type:     types.StringType,
+			},
+			"docker_host": {
+				MarkdownDescription: "Docker daemon address used by k3d, for example " +
+					"`unix:///run/user/1000/docker.sock` for rootless Docker or `ssh://user@host` for a remote daemon. " +
+					"Defaults to the `DOCKER_HOST` environment variable of the Terraform process.",
+				Optional: true,
+				Type:     types.StringType,
+			},
+			"env": {
+				MarkdownDescription: "Additional environment variables for every k3d command.",
+				Optional:            true,
+				Type:                types.MapType{ElemType: types.StringType},
+			},
+			"command_timeout": {
+				MarkdownDescription: "Default timeout for every k3d command as a duration such as `10m` or `90s`. " +
+					"Commands do not time out by default.",
+				Optional: true,
+				Type:     types.StringType,
+			},
+		},
 	}, nil
 }
 
@@ -60,23 +94,56 @@ func (p *K3dProvider) Configure(ctx context.Context, req provider.ConfigureReque
 		return
 	}
 
-	// Configuration values are now available.
-	// if data.Endpoint.IsNull() { /* ... */ }
+	// Data sources and resources run k3d through the runner.
+	runner := NewExecK3dRunner()
+
+	if !data.K3dPath.IsNull() {
+		runner.Path = data.K3dPath.ValueString()
+	}
+
+	if !data.DockerHost.IsNull() {
+		runner.DockerHost = data.DockerHost.ValueString()
+	}
+
+	if !data.Env.IsNull() {
+		resp.Diagnostics.Append(data.Env.ElementsAs(ctx, &runner.Env, false)...)
+	}
 
-	// Example client configuration for data sources and resources
-	client := http.DefaultClient
-	resp.DataSourceData = client
-	resp.ResourceData = client
+	if !data.CommandTimeout.IsNull() {
+		timeout, err := time.ParseDuration(data.CommandTimeout.ValueString())
+		if err != nil || timeout <= 0 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("command_timeout"),
+				"Invalid command timeout",
+				fmt.Sprintf("Expected a positive duration such as \"10m\", got: %q.", data.CommandTimeout.ValueString()),
+			)
+		}
+		runner.Timeout = timeout
+	}
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.DataSourceData = runner
+	resp.ResourceData = runner
 }
 
 func (p *K3dProvider) Resources(ctx context.Context) []func() resource.Resource {
 	return []func() resource.Resource{
 		NewClusterResource,
+		NewRegistryResource,
+		NewImageImportResource,
+		NewNodeResource,
 	}
 }
 
 func (p *K3dProvider) DataSources(ctx context.Context) []func() datasource.DataSource {
-	return []func() datasource.DataSource{}
+	return []func() datasource.DataSource{
+		NewClusterDataSource,
+		NewClustersDataSource,
+		NewKubeconfigDataSource,
+	}
 }
 
 func New(version string) func() provider.Provider {
@@ -1,10 +1,20 @@
 package provider
 
 import (
+	"context"
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	fwresource "github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
 )
 
 // testAccProtoV6ProviderFactories are used to instantiate a provider during
@@ -20,3 +30,166 @@ func testAccPreCheck(t *testing.T) {
 	// about the appropriate environment variables being set are common to see in a pre-check
 	// function.
 }
+
+func TestProviderConfigure(t *testing.T) {
+	p := New("test")()
+	resp := &provider.ConfigureResponse{}
+	p.Configure(context.Background(), provider.ConfigureRequest{Config: newTestProviderConfig(t, K3dProviderModel{
+		K3dPath:        types.StringValue("/opt/k3d/bin/k3d"),
+		DockerHost:     types.StringValue("ssh://user@remote"),
+		Env:            types.MapValueMust(types.StringType, map[string]attr.Value{"K3D_FIX_DNS": types.StringValue("1")}),
+		CommandTimeout: types.StringValue("5m"),
+	})}, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+	runner, ok := resp.ResourceData.(*ExecK3dRunner)
+	if !ok {
+		t.Fatalf("expected *ExecK3dRunner resource data, got %T", resp.ResourceData)
+	}
+	if runner.Path != "/opt/k3d/bin/k3d" {
+		t.Errorf("expected path /opt/k3d/bin/k3d, got %s", runner.Path)
+	}
+	if runner.DockerHost != "ssh://user@remote" {
+		t.Errorf("expected docker host ssh://user@remote, got %s", runner.DockerHost)
+	}
+	if runner.Env["K3D_FIX_DNS"] != "1" {
+		t.Errorf("expected env K3D_FIX_DNS=1, got %v", runner.Env)
+	}
+	if runner.Timeout != 5*time.Minute {
+		t.Errorf("expected timeout 5m, got %s", runner.Timeout)
+	}
+}
+
+func TestProviderConfigureDefaults(t *testing.T) {
+	p := New("test")()
+	resp := &provider.ConfigureResponse{}
+	p.Configure(context.Background(), provider.ConfigureRequest{Config: newTestProviderConfig(t, K3dProviderModel{})}, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+	runner := resp.ResourceData.(*ExecK3dRunner)
+	if runner.Path != "k3d" {
+		t.Errorf("expected path k3d, got %s", runner.Path)
+	}
+	if runner.Timeout != 0 {
+		t.Errorf("expected no timeout, got %s", runner.Timeout)
+	}
+}
+
+func TestProviderConfigureInvalidTimeout(t *testing.T) {
+	p := New("test")()
+	resp := &provider.ConfigureResponse{}
+	p.Configure(context.Background(), provider.ConfigureRequest{Config: newTestProviderConfig(t, K3dProviderModel{
+		CommandTimeout: types.StringValue("ten minutes"),
+	})}, resp)
+
+	if !resp.Diagnostics.HasError() {
+		t.Fatal("expected error")
+	}
+	if resp.ResourceData != nil {
+		t.Error("expected resource data not to be set")
+	}
+}
+
+func newTestProviderConfig(t *testing.T, data K3dProviderModel) tfsdk.Config {
+	schema, diags := (&K3dProvider{}).GetSchema(context.Background())
+	if diags.HasError() {
+		t.Fatalf("unexpected schema error: %v", diags)
+	}
+	if data.Env.IsNull() {
+		data.Env = types.MapNull(types.StringType)
+	}
+	return tfsdk.Config{Schema: schema, Raw: newTestState(t, schema, &data).Raw}
+}
+
+// testResourceSchema returns the schema of r.
+func testResourceSchema(t *testing.T, r fwresource.Resource) tfsdk.Schema {
+	schema, diags := r.GetSchema(context.Background())
+	if diags.HasError() {
+		t.Fatalf("unexpected schema error: %v", diags)
+	}
+	return schema
+}
+
+// newTestState returns a state of schema holding data, or an empty state
+// when data is nil.
+func newTestState(t *testing.T, schema tfsdk.Schema, data any) tfsdk.State {
+	state := tfsdk.State{
+		Schema: schema,
+		Raw:    tftypes.NewValue(schema.Type().TerraformType(context.Background()), nil),
+	}
+	if data != nil {
+		if diags := state.Set(context.Background(), data); diags.HasError() {
+			t.Fatalf("unexpected state error: %v", diags)
+		}
+	}
+	return state
+}
+
+func newTestPlan(t *testing.T, schema tfsdk.Schema, data any) tfsdk.Plan {
+	state := newTestState(t, schema, data)
+	return tfsdk.Plan{Schema: state.Schema, Raw: state.Raw}
+}
+
+func getTestModel[T any](t *testing.T, state tfsdk.State) T {
+	var data T
+	if diags := state.Get(context.Background(), &data); diags.HasError() {
+		t.Fatalf("unexpected state error: %v", diags)
+	}
+	return data
+}
+
+// fakeK3dRunner is a scriptable K3dRunner used to test resources without
+// Docker or k3d. Each command is answered by the first response whose
+// arguments are a prefix of the command arguments.
+type fakeK3dRunner struct {
+	responses []fakeK3dResponse
+	calls     [][]string
+}
+
+type fakeK3dResponse struct {
+	args   []string
+	output string
+	err    error
+}
+
+// On scripts the output and error returned for commands starting with args.
+func (f *fakeK3dRunner) On(output string, err error, args ...string) *fakeK3dRunner {
+	f.responses = append(f.responses, fakeK3dResponse{args: args, output: output, err: err})
+	return f
+}
+
+func (f *fakeK3dRunner) Run(ctx context.Context, args ...string) ([]byte, error) {
+	f.calls = append(f.calls, args)
+	for _, response := range f.responses {
+		if hasArgsPrefix(args, response.args) {
+			return []byte(response.output), response.err
+		}
+	}
+	return nil, fmt.Errorf("unexpected command: k3d %s", strings.Join(args, " "))
+}
+
+// Called reports whether a command starting with args was run.
+func (f *fakeK3dRunner) Called(args ...string) bool {
+	for _, call := range f.calls {
+		if hasArgsPrefix(call, args) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasArgsPrefix(args []string, prefix []string) bool {
+	if len(prefix) > len(args) {
+		return false
+	}
+	for i := range prefix {
+		if args[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
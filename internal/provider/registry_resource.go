@@ -0,0 +1,329 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces
+var _ resource.Resource = &RegistryResource{}
+var _ resource.ResourceWithImportState = &RegistryResource{}
+
+func NewRegistryResource() resource.Resource {
+	return &RegistryResource{}
+}
+
+// RegistryResource defines the resource implementation.
+type RegistryResource struct {
+	runner K3dRunner
+}
+
+// RegistryResourceModel describes the resource data model.
+type RegistryResourceModel struct {
+	ID             types.String `tfsdk:"id"`
+	Name           types.String `tfsdk:"name"`
+	HostPort       types.Int64  `tfsdk:"host_port"`
+	Image          types.String `tfsdk:"image"`
+	ProxyRemoteURL types.String `tfsdk:"proxy_remote_url"`
+	Host           types.String `tfsdk:"host"`
+	Running        types.Bool   `tfsdk:"running"`
+}
+
+func (r *RegistryResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_registry"
+}
+
+func (r *RegistryResource) GetSchema(ctx context.Context) (tfsdk.Schema, diag.Diagnostics) {
+	return tfsdk.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "The resource `k3d_registry` manages k3d container registries.\n" +
+			"\n" +
+			"Unlike registries created by the `registries.create` option of a cluster config, " +
+			"the registry is not deleted with a cluster, so several clusters can share it. " +
+			"Use the registry in a cluster with the `registries.use` option, " +
+			"such as `${k3d_registry.example.host}:${k3d_registry.example.host_port}`.\n" +
+			"\n" +
+			"k3d does not support updating registries, so changing any attribute replaces the registry. " +
+			"Existing registries can be imported by name.",
+
+		Attributes: map[string]tfsdk.Attribute{
+			"id": {
+				MarkdownDescription: "Registry name.",
+				Type:                types.StringType,
+				Computed:            true,
+				PlanModifiers: tfsdk.AttributePlanModifiers{
+					resource.UseStateForUnknown(),
+				},
+			},
+			"name": {
+				MarkdownDescription: "Registry name, without the `k3d-` prefix k3d adds to the registry container. " +
+					"Changing the name forces replacement.",
+				Type:     types.StringType,
+				Required: true,
+				PlanModifiers: tfsdk.AttributePlanModifiers{
+					resource.RequiresReplace(),
+				},
+			},
+			"host_port": {
+				MarkdownDescription: "Port on the host the registry is exposed on. " +
+					"A free port is chosen by k3d when not set. Changing the port forces replacement.",
+				Type:     types.Int64Type,
+				Optional: true,
+				Computed: true,
+				PlanModifiers: tfsdk.AttributePlanModifiers{
+					resource.UseStateForUnknown(),
+					resource.RequiresReplace(),
+				},
+			},
+			"image": {
+				MarkdownDescription: "Registry image. Defaults to the image of the installed k3d version, " +
+					"such as `docker.io/library/registry:2`. Changing the image forces replacement.",
+				Type:     types.StringType,
+				Optional: true,
+				Computed: true,
+				PlanModifiers: tfsdk.AttributePlanModifiers{
+					resource.UseStateForUnknown(),
+					resource.RequiresReplace(),
+				},
+			},
+			"proxy_remote_url": {
+				MarkdownDescription: "URL of a registry to proxy, which makes the registry a pull-through cache, " +
+					"such as `https://registry-1.docker.io`. Changing the URL forces replacement.",
+				Type:     types.StringType,
+				Optional: true,
+				PlanModifiers: tfsdk.AttributePlanModifiers{
+					resource.RequiresReplace(),
+				},
+			},
+			"host": {
+				MarkdownDescription: "Host name of the registry container, such as `k3d-example`. " +
+					"Clusters using the registry reach it at this host name.",
+				Type:     types.StringType,
+				Computed: true,
+				PlanModifiers: tfsdk.AttributePlanModifiers{
+					resource.UseStateForUnknown(),
+				},
+			},
+			"running": {
+				MarkdownDescription: "Whether the registry container is running.",
+				Type:                types.BoolType,
+				Computed:            true,
+				PlanModifiers: tfsdk.AttributePlanModifiers{
+					resource.UseStateForUnknown(),
+				},
+			},
+		},
+	}, nil
+}
+
+func (r *RegistryResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	runner, ok := req.ProviderData.(K3dRunner)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected K3dRunner, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.runner = runner
+}
+
+func (r *RegistryResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data *RegistryResourceModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	args := []string{"registry", "create", data.Name.ValueString()}
+	if !data.HostPort.IsNull() && !data.HostPort.IsUnknown() {
+		args = append(args, "--port", strconv.FormatInt(data.HostPort.ValueInt64(), 10))
+	}
+	if !data.Image.IsNull() && !data.Image.IsUnknown() {
+		args = append(args, "--image", data.Image.ValueString())
+	}
+	if !data.ProxyRemoteURL.IsNull() {
+		args = append(args, "--proxy-remote-url", data.ProxyRemoteURL.ValueString())
+	}
+
+	output, err := r.runner.Run(withK3dPhase(ctx, "create"), args...)
+	if err != nil {
+		addK3dError(&resp.Diagnostics, "Failed creating k3d registry", k3dObject{Kind: "registry", Name: registryContainerName(data.Name.ValueString()), NamePath: path.Root("name")}, output, err)
+		return
+	}
+	tflog.Info(ctx, "created registry", map[string]interface{}{"name": data.Name.ValueString()})
+
+	registries, diags := listRegistries(ctx, r.runner)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	registry, err := findRegistry(registries, data.Name.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Failed reading created k3d registry", fmt.Sprint(err))
+		return
+	}
+	data.ID = data.Name
+	setRegistryAttributes(data, registry)
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *RegistryResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data *RegistryResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	registries, diags := listRegistries(ctx, r.runner)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	registry, err := findRegistry(registries, data.Name.ValueString())
+	if err != nil {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	setRegistryAttributes(data, registry)
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *RegistryResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data *RegistryResourceModel
+
+	// Every configurable attribute forces replacement, so there is nothing to
+	// update in k3d.
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *RegistryResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data *RegistryResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	name := registryContainerName(data.Name.ValueString())
+	if output, err := r.runner.Run(withK3dPhase(ctx, "delete"), "registry", "delete", name); err != nil {
+		addK3dError(&resp.Diagnostics, "Failed deleting k3d registry", k3dObject{Kind: "registry", Name: name}, output, err)
+		return
+	}
+}
+
+func (r *RegistryResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	// The other attributes are set by Read after the import.
+	name := strings.TrimPrefix(req.ID, "k3d-")
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), name)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("name"), name)...)
+}
+
+// listRegistries lists the k3d registries.
+func listRegistries(ctx context.Context, runner K3dRunner) ([]K3dNodeInfo, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	output, err := runner.Run(ctx, "registry", "list", "--output", "json")
+	if err != nil {
+		addK3dError(&diags, "Failed listing k3d registries", k3dObject{}, output, err)
+		return nil, diags
+	}
+	// k3d prints nothing instead of an empty list when there are no registries.
+	if strings.TrimSpace(string(output)) == "" {
+		return nil, diags
+	}
+	var registries []K3dNodeInfo
+	if err := json.Unmarshal(output, &registries); err != nil {
+		diags.AddError("Failed parsing k3d registry list", fmt.Sprint(err))
+		return nil, diags
+	}
+	return registries, diags
+}
+
+func findRegistry(registries []K3dNodeInfo, name string) (K3dNodeInfo, error) {
+	for _, registry := range registries {
+		if registry.Name == registryContainerName(name) {
+			return registry, nil
+		}
+	}
+	return K3dNodeInfo{}, fmt.Errorf("registries does not contain a registry with matching name")
+}
+
+// registryContainerName returns the name of the container of the registry
+// called name, which k3d prefixes with `k3d-`.
+func registryContainerName(name string) string {
+	return "k3d-" + strings.TrimPrefix(name, "k3d-")
+}
+
+// containerImage returns the image of the container for the image attribute
+// configured as image. A configured image is kept, because it may be a
+// different reference to the same image than the one Docker reports.
+func containerImage(image types.String, container K3dNodeInfo) types.String {
+	if image.IsNull() || image.IsUnknown() {
+		return types.StringValue(container.Image)
+	}
+	return image
+}
+
+// setRegistryAttributes sets the attributes observed on the registry
+// container on data.
+func setRegistryAttributes(data *RegistryResourceModel, registry K3dNodeInfo) {
+	data.Host = types.StringValue(registry.Name)
+	data.Image = containerImage(data.Image, registry)
+	data.Running = types.BoolValue(registry.State.Running)
+	if data.HostPort.IsUnknown() {
+		data.HostPort = types.Int64Null()
+	}
+
+	// The registry listens on port 5000 in the container.
+	for _, binding := range registry.PortMappings["5000/tcp"] {
+		if port, err := strconv.ParseInt(binding.HostPort, 10, 64); err == nil {
+			data.HostPort = types.Int64Value(port)
+			break
+		}
+	}
+
+	// The proxy is configured through the environment of the registry.
+	for _, env := range registry.Env {
+		if value := strings.TrimPrefix(env, "REGISTRY_PROXY_REMOTEURL="); value != env {
+			data.ProxyRemoteURL = types.StringValue(value)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	fwresource "github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccRegistryResource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccRegistryResourceConfig(),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("k3d_registry.test", "name", "provider-test"),
+					resource.TestCheckResourceAttr("k3d_registry.test", "host", "k3d-provider-test"),
+					resource.TestCheckResourceAttr("k3d_registry.test", "host_port", "5123"),
+					resource.TestCheckResourceAttr("k3d_registry.test", "running", "true"),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:      "k3d_registry.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
+
+func testAccRegistryResourceConfig() string {
+	return `
+resource "k3d_registry" "test" {
+	name      = "provider-test"
+	host_port = 5123
+}
+`
+}
+
+const testRegistryList = `[
+	{
+		"name": "k3d-dev",
+		"role": "registry",
+		"image": "docker.io/library/registry:2",
+		"portMappings": {"5000/tcp": [{"HostIp": "0.0.0.0", "HostPort": "5000"}]},
+		"env": ["REGISTRY_PROXY_REMOTEURL=https://registry-1.docker.io"],
+		"State": {"Running": true, "Status": "running"}
+	}
+]`
+
+func TestRegistryResourceCreate(t *testing.T) {
+	runner := (&fakeK3dRunner{}).
+		On("", nil, "registry", "create", "dev").
+		On(testRegistryList, nil, "registry", "list")
+	r := &RegistryResource{runner: runner}
+
+	req := fwresource.CreateRequest{Plan: newTestPlan(t, testResourceSchema(t, &RegistryResource{}), RegistryResourceModel{
+		Name:           types.StringValue("dev"),
+		HostPort:       types.Int64Value(5000),
+		Image:          types.StringUnknown(),
+		ProxyRemoteURL: types.StringValue("https://registry-1.docker.io"),
+	})}
+	resp := &fwresource.CreateResponse{State: newTestState(t, testResourceSchema(t, &RegistryResource{}), nil)}
+	r.Create(context.Background(), req, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+	if !runner.Called("registry", "create", "dev", "--port", "5000", "--proxy-remote-url", "https://registry-1.docker.io") {
+		t.Errorf("expected registry create with port and proxy, got %v", runner.calls)
+	}
+	data := getTestModel[RegistryResourceModel](t, resp.State)
+	if got := data.Host.ValueString(); got != "k3d-dev" {
+		t.Errorf("expected host k3d-dev, got %s", got)
+	}
+	if got := data.Image.ValueString(); got != "docker.io/library/registry:2" {
+		t.Errorf("expected default image, got %s", got)
+	}
+	if got := data.ID.ValueString(); got != "dev" {
+		t.Errorf("expected id dev, got %s", got)
+	}
+}
+
+func TestRegistryResourceCreateAlreadyExists(t *testing.T) {
+	runner := (&fakeK3dRunner{}).
+		On("FATA[0000] Failed to create registry: registry 'k3d-dev' already exists", errors.New("exit status 1"), "registry", "create", "dev")
+	r := &RegistryResource{runner: runner}
+
+	req := fwresource.CreateRequest{Plan: newTestPlan(t, testResourceSchema(t, &RegistryResource{}), RegistryResourceModel{
+		Name:     types.StringValue("dev"),
+		HostPort: types.Int64Unknown(),
+		Image:    types.StringUnknown(),
+	})}
+	resp := &fwresource.CreateResponse{State: newTestState(t, testResourceSchema(t, &RegistryResource{}), nil)}
+	r.Create(context.Background(), req, resp)
+
+	if !resp.Diagnostics.HasError() {
+		t.Fatal("expected error")
+	}
+	if got := resp.Diagnostics[0].Summary(); got != "Registry already exists" {
+		t.Errorf("expected already exists error, got %q", got)
+	}
+	if withPath, ok := resp.Diagnostics[0].(diag.DiagnosticWithPath); !ok || !withPath.Path().Equal(path.Root("name")) {
+		t.Errorf("expected diagnostic for name, got %v", resp.Diagnostics[0])
+	}
+}
+
+func TestRegistryResourceRead(t *testing.T) {
+	runner := (&fakeK3dRunner{}).
+		On(testRegistryList, nil, "registry", "list")
+	r := &RegistryResource{runner: runner}
+
+	// Imported registries only have a name.
+	state := newTestState(t, testResourceSchema(t, &RegistryResource{}), &RegistryResourceModel{
+		ID:   types.StringValue("dev"),
+		Name: types.StringValue("dev"),
+	})
+	resp := &fwresource.ReadResponse{State: state}
+	r.Read(context.Background(), fwresource.ReadRequest{State: state}, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+	data := getTestModel[RegistryResourceModel](t, resp.State)
+	if got := data.HostPort.ValueInt64(); got != 5000 {
+		t.Errorf("expected host_port 5000, got %d", got)
+	}
+	if got := data.ProxyRemoteURL.ValueString(); got != "https://registry-1.docker.io" {
+		t.Errorf("expected proxy_remote_url from the registry environment, got %s", got)
+	}
+	if !data.Running.ValueBool() {
+		t.Error("expected running to be true")
+	}
+}
+
+func TestRegistryResourceReadMissing(t *testing.T) {
+	// k3d prints nothing when there are no registries.
+	runner := (&fakeK3dRunner{}).
+		On("", nil, "registry", "list")
+	r := &RegistryResource{runner: runner}
+
+	state := newTestState(t, testResourceSchema(t, &RegistryResource{}), &RegistryResourceModel{
+		ID:   types.StringValue("dev"),
+		Name: types.StringValue("dev"),
+	})
+	resp := &fwresource.ReadResponse{State: state}
+	r.Read(context.Background(), fwresource.ReadRequest{State: state}, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+	if !resp.State.Raw.IsNull() {
+		t.Error("expected resource to be removed from state")
+	}
+}
+
+func TestRegistryResourceDelete(t *testing.T) {
+	runner := (&fakeK3dRunner{}).
+		On("", nil, "registry", "delete", "k3d-dev")
+	r := &RegistryResource{runner: runner}
+
+	state := newTestState(t, testResourceSchema(t, &RegistryResource{}), &RegistryResourceModel{
+		ID:   types.StringValue("dev"),
+		Name: types.StringValue("dev"),
+	})
+	resp := &fwresource.DeleteResponse{State: state}
+	r.Delete(context.Background(), fwresource.DeleteRequest{State: state}, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+	if !runner.Called("registry", "delete", "k3d-dev") {
+		t.Errorf("expected registry delete, got %v", runner.calls)
+	}
+}
+
+func TestRegistryResourceImportState(t *testing.T) {
+	r := &RegistryResource{}
+
+	resp := &fwresource.ImportStateResponse{State: newTestState(t, testResourceSchema(t, &RegistryResource{}), nil)}
+	r.ImportState(context.Background(), fwresource.ImportStateRequest{ID: "k3d-dev"}, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+	data := getTestModel[RegistryResourceModel](t, resp.State)
+	if got := data.Name.ValueString(); got != "dev" {
+		t.Errorf("expected name dev without prefix, got %s", got)
+	}
+}
@@ -0,0 +1,223 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure ExecK3dRunner fully satisfies the K3dRunner interface.
+var _ K3dRunner = &ExecK3dRunner{}
+
+// K3dRunner runs k3d commands on behalf of resources and data sources.
+//
+// Resources never call the k3d binary directly, which allows tests to replace
+// the runner with a fake that does not require Docker or k3d.
+type K3dRunner interface {
+	// Run runs k3d with the given arguments and returns its combined output.
+	Run(ctx context.Context, args ...string) ([]byte, error)
+}
+
+// ExecK3dRunner runs k3d commands by executing the k3d binary.
+type ExecK3dRunner struct {
+	// Path is the k3d binary to execute, looked up in $PATH when it is not
+	// an absolute path.
+	Path string
+	// DockerHost is passed to k3d as the DOCKER_HOST environment variable
+	// when it is not empty.
+	DockerHost string
+	// Env holds additional environment variables for k3d. The provider
+	// process environment is always inherited.
+	Env map[string]string
+	// Timeout limits the duration of every k3d command when it is positive.
+	Timeout time.Duration
+}
+
+func NewExecK3dRunner() *ExecK3dRunner {
+	return &ExecK3dRunner{
+		Path: "k3d",
+	}
+}
+
+func (r *ExecK3dRunner) Run(ctx context.Context, args ...string) ([]byte, error) {
+	if r.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.Timeout)
+		defer cancel()
+	}
+
+	// The k3d process is killed when the context is done, such as when the
+	// operation times out or Terraform is interrupted.
+	cmd := exec.CommandContext(ctx, r.Path, args...)
+	cmd.Env = r.environ()
+
+	// Stdout and stderr share the writer, so it is never written concurrently.
+	writer := &k3dOutputWriter{ctx: ctx, stream: k3dPhase(ctx) != ""}
+	cmd.Stdout = writer
+	cmd.Stderr = writer
+	err := cmd.Run()
+	writer.Flush()
+
+	if err != nil && ctx.Err() != nil {
+		return writer.output.Bytes(), fmt.Errorf("k3d was stopped: %w", ctx.Err())
+	}
+	return writer.output.Bytes(), err
+}
+
+func (r *ExecK3dRunner) environ() []string {
+	return commandEnviron(r.DockerHost, r.Env)
+}
+
+// commandEnviron returns the environment of the provider process with the
+// Docker host and the additional variables of the provider configuration.
+func commandEnviron(dockerHost string, extra map[string]string) []string {
+	env := os.Environ()
+	if dockerHost != "" {
+		env = append(env, "DOCKER_HOST="+dockerHost)
+	}
+	for key, value := range extra {
+		env = append(env, key+"="+value)
+	}
+	return env
+}
+
+// Ensure ExecDockerRunner fully satisfies the DockerRunner interface.
+var _ DockerRunner = &ExecDockerRunner{}
+
+// errDockerUnavailable is returned by docker runners when the docker CLI is
+// not installed.
+var errDockerUnavailable = errors.New("the docker CLI is not available")
+
+// DockerRunner runs docker CLI commands. k3d itself does not need the docker
+// CLI, so resources only use it for optional checks and keep working without
+// it.
+type DockerRunner interface {
+	// Run runs docker with the given arguments and returns its combined
+	// output.
+	Run(ctx context.Context, args ...string) ([]byte, error)
+}
+
+// ExecDockerRunner runs docker commands by executing the docker binary.
+type ExecDockerRunner struct {
+	// Path is the docker binary to execute, looked up in $PATH when it is not
+	// an absolute path.
+	Path string
+	// DockerHost is passed to docker as the DOCKER_HOST environment variable
+	// when it is not empty.
+	DockerHost string
+	// Env holds additional environment variables for docker.
+	Env map[string]string
+	// Timeout limits the duration of every docker command when it is
+	// positive.
+	Timeout time.Duration
+}
+
+// newExecDockerRunner returns a docker runner using the Docker host,
+// environment and timeout of the k3d runner, so both talk to the same Docker
+// daemon. It returns nil when runner does not execute k3d.
+func newExecDockerRunner(runner K3dRunner) DockerRunner {
+	execRunner, ok := runner.(*ExecK3dRunner)
+	if !ok {
+		return nil
+	}
+	return &ExecDockerRunner{
+		Path:       "docker",
+		DockerHost: execRunner.DockerHost,
+		Env:        execRunner.Env,
+		Timeout:    execRunner.Timeout,
+	}
+}
+
+func (r *ExecDockerRunner) Run(ctx context.Context, args ...string) ([]byte, error) {
+	if _, err := exec.LookPath(r.Path); err != nil {
+		return nil, fmt.Errorf("%w: %s", errDockerUnavailable, err)
+	}
+	if r.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.Timeout)
+		defer cancel()
+	}
+
+	// The output of docker commands is not logged.
+	cmd := exec.CommandContext(ctx, r.Path, args...)
+	cmd.Env = commandEnviron(r.DockerHost, r.Env)
+	return cmd.CombinedOutput()
+}
+
+type k3dPhaseKey struct{}
+
+// withK3dPhase returns a context which streams the output of k3d commands to
+// the Terraform logs, with the phase such as `create` or `delete` as a field.
+// Output of other commands is not logged, since it can contain credentials.
+func withK3dPhase(ctx context.Context, phase string) context.Context {
+	ctx = tflog.SetField(ctx, "phase", phase)
+	return context.WithValue(ctx, k3dPhaseKey{}, phase)
+}
+
+// k3dPhase returns the phase set by withK3dPhase, or an empty string.
+func k3dPhase(ctx context.Context) string {
+	phase, _ := ctx.Value(k3dPhaseKey{}).(string)
+	return phase
+}
+
+// k3dOutputWriter collects the output of a k3d command and, when stream is
+// set, logs every line as soon as it is complete.
+type k3dOutputWriter struct {
+	ctx     context.Context
+	stream  bool
+	output  bytes.Buffer
+	pending []byte
+}
+
+func (w *k3dOutputWriter) Write(p []byte) (int, error) {
+	w.output.Write(p)
+	if !w.stream {
+		return len(p), nil
+	}
+
+	w.pending = append(w.pending, p...)
+	for {
+		i := bytes.IndexByte(w.pending, '\n')
+		if i < 0 {
+			break
+		}
+		w.log(string(w.pending[:i]))
+		w.pending = w.pending[i+1:]
+	}
+	return len(p), nil
+}
+
+// Flush logs the last line when the output does not end with a newline.
+func (w *k3dOutputWriter) Flush() {
+	if len(w.pending) > 0 {
+		w.log(string(w.pending))
+		w.pending = nil
+	}
+}
+
+// log logs a line of k3d output at the level k3d logged it with, such as
+// `WARN[0003] ...`.
+func (w *k3dOutputWriter) log(line string) {
+	line = strings.TrimRight(line, "\r")
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+
+	switch {
+	case strings.HasPrefix(line, "ERRO"), strings.HasPrefix(line, "FATA"):
+		tflog.Error(w.ctx, line)
+	case strings.HasPrefix(line, "WARN"):
+		tflog.Warn(w.ctx, line)
+	case strings.HasPrefix(line, "DEBU"), strings.HasPrefix(line, "TRAC"):
+		tflog.Debug(w.ctx, line)
+	default:
+		tflog.Info(w.ctx, line)
+	}
+}
@@ -0,0 +1,124 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+)
+
+func TestExecK3dRunnerEnviron(t *testing.T) {
+	t.Setenv("DOCKER_HOST", "unix:///var/run/docker.sock")
+	runner := &ExecK3dRunner{
+		Path:       "k3d",
+		DockerHost: "ssh://user@remote",
+		Env:        map[string]string{"K3D_FIX_DNS": "1"},
+	}
+
+	env := runner.environ()
+
+	// Later entries take precedence when running the command.
+	var dockerHost, fixDNS string
+	for _, entry := range env {
+		switch entry {
+		case "DOCKER_HOST=unix:///var/run/docker.sock", "DOCKER_HOST=ssh://user@remote":
+			dockerHost = entry
+		case "K3D_FIX_DNS=1":
+			fixDNS = entry
+		}
+	}
+	if dockerHost != "DOCKER_HOST=ssh://user@remote" {
+		t.Errorf("expected docker_host to override DOCKER_HOST, got %q", dockerHost)
+	}
+	if fixDNS == "" {
+		t.Error("expected env to contain K3D_FIX_DNS")
+	}
+}
+
+func TestExecK3dRunnerTimeout(t *testing.T) {
+	// Any long running command stands in for a hanging k3d.
+	runner := &ExecK3dRunner{Path: "sleep", Timeout: 10 * time.Millisecond}
+
+	_, err := runner.Run(context.Background(), "10")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded error, got %v", err)
+	}
+}
+
+func TestExecK3dRunnerStreamsOutput(t *testing.T) {
+	var logs bytes.Buffer
+	ctx := tflogtest.RootLogger(context.Background(), &logs)
+	ctx = tflog.SetField(ctx, "name", "test")
+	runner := &ExecK3dRunner{Path: "sh"}
+
+	output, err := runner.Run(withK3dPhase(ctx, "create"), "-c", "echo 'INFO[0000] Prep: Network'; echo 'WARN[0001] No node filter' >&2; printf done")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := string(output); got != "INFO[0000] Prep: Network\nWARN[0001] No node filter\ndone" {
+		t.Errorf("expected combined output, got %q", got)
+	}
+
+	entries, err := tflogtest.MultilineJSONDecode(&logs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []struct{ level, message string }{
+		{"info", "INFO[0000] Prep: Network"},
+		{"warn", "WARN[0001] No node filter"},
+		{"info", "done"},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("expected %d log entries, got %v", len(want), entries)
+	}
+	for i, entry := range entries {
+		if entry["@level"] != want[i].level || entry["@message"] != want[i].message {
+			t.Errorf("expected %s entry %q, got %v", want[i].level, want[i].message, entry)
+		}
+		if entry["phase"] != "create" || entry["name"] != "test" {
+			t.Errorf("expected phase and name fields, got %v", entry)
+		}
+	}
+}
+
+func TestExecK3dRunnerDoesNotLogWithoutPhase(t *testing.T) {
+	var logs bytes.Buffer
+	ctx := tflogtest.RootLogger(context.Background(), &logs)
+	runner := &ExecK3dRunner{Path: "sh"}
+
+	if _, err := runner.Run(ctx, "-c", "echo client-key-data: secret"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if logs.Len() != 0 {
+		t.Errorf("expected no logs, got %s", logs.String())
+	}
+}
+
+func TestExecDockerRunnerUnavailable(t *testing.T) {
+	runner := &ExecDockerRunner{Path: filepath.Join(t.TempDir(), "docker")}
+
+	_, err := runner.Run(context.Background(), "version")
+	if !errors.Is(err, errDockerUnavailable) {
+		t.Errorf("expected docker to be unavailable, got %v", err)
+	}
+}
+
+func TestNewExecDockerRunner(t *testing.T) {
+	runner := &ExecK3dRunner{Path: "/usr/local/bin/k3d", DockerHost: "ssh://user@remote", Timeout: time.Minute}
+
+	docker, ok := newExecDockerRunner(runner).(*ExecDockerRunner)
+	if !ok {
+		t.Fatal("expected docker runner")
+	}
+	if docker.Path != "docker" || docker.DockerHost != "ssh://user@remote" || docker.Timeout != time.Minute {
+		t.Errorf("expected docker runner sharing the Docker host and timeout, got %+v", docker)
+	}
+	if newExecDockerRunner(&fakeK3dRunner{}) != nil {
+		t.Error("expected no docker runner for other runners")
+	}
+}